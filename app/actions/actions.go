@@ -0,0 +1,343 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"sort"
+	"strings"
+)
+
+// Scope tells where an action works
+type Scope string
+
+const (
+	Global   Scope = "Global"
+	List     Scope = "Lists"
+	Marks    Scope = "Marks"
+	Menu     Scope = "Menu"
+	Resource Scope = "Resources"
+	Popup    Scope = "Popups"
+	Theme    Scope = "Theme editor"
+)
+
+// Order of scopes in help. Kind scopes go right after Resource
+var scopeOrder = []Scope{Global, Menu, List, Marks, Resource, Popup, Theme}
+
+// Kind returns scope of actions which only work with the resource kind
+func Kind(kind string) Scope {
+	return Scope(kind)
+}
+
+// isKind reports whether the scope is a kind scope
+func (s Scope) isKind() bool {
+	for _, scope := range scopeOrder {
+		if scope == s {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Scope) rank() float64 {
+	for i, scope := range scopeOrder {
+		if scope == s {
+			return float64(i)
+		}
+	}
+	for i, scope := range scopeOrder {
+		if scope == Resource {
+			return float64(i) + 0.5
+		}
+	}
+	return float64(len(scopeOrder))
+}
+
+// Scoped is implemented by widgets to tell which actions work in them
+type Scoped interface {
+	ActionScopes() []Scope
+}
+
+// ScopesOf returns scopes of actions working in the widget, followed by global ones
+func ScopesOf(widget interface{}) []Scope {
+	var scopes []Scope
+	if scoped, ok := widget.(Scoped); ok {
+		scopes = append(scopes, scoped.ActionScopes()...)
+	}
+	return append(scopes, Global, Theme)
+}
+
+// Enabler is implemented by widgets which can't do some of their actions at the moment, like without a selected row
+type Enabler interface {
+	ActionEnabled(a *Action) bool
+}
+
+// Available returns bound actions of the scopes which the widget could do now
+func Available(widget interface{}, scopes []Scope) []*Action {
+	enabler, _ := widget.(Enabler)
+	var available []*Action
+	for _, scope := range scopes {
+		for _, a := range Bound(scope) {
+			if enabler == nil || enabler.ActionEnabled(a) {
+				available = append(available, a)
+			}
+		}
+	}
+	return available
+}
+
+// Scopes returns all scopes in help order
+func Scopes() []Scope {
+	var scopes []Scope
+	seen := make(map[Scope]bool)
+	for _, a := range registry {
+		if !seen[a.Scope] {
+			seen[a.Scope] = true
+			scopes = append(scopes, a.Scope)
+		}
+	}
+	sort.SliceStable(scopes, func(i, j int) bool {
+		return scopes[i].rank() < scopes[j].rank()
+	})
+	return scopes
+}
+
+// Action is something user can do by pressing keys
+type Action struct {
+	Id          string
+	Description string
+	Scope       Scope
+	keys        []Key
+	defaults    []Key
+}
+
+// Keys returns keys bound to the action
+func (a *Action) Keys() []Key {
+	return a.keys
+}
+
+// KeyNames returns keys bound to the action separated by commas
+func (a *Action) KeyNames() string {
+	var names []string
+	for _, key := range a.keys {
+		names = append(names, key.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// Matches reports whether the event is one of the keys bound to the action
+func (a *Action) Matches(ev *tcell.EventKey) bool {
+	for _, key := range a.keys {
+		if key.Matches(ev) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	registry []*Action
+	byId     = make(map[string]*Action)
+)
+
+// Handler is implemented by widgets which run actions directly, without their keys being pressed,
+// like when an action is picked in the palette
+type Handler interface {
+	// HandleAction runs the action and reports whether the widget has done it
+	HandleAction(a *Action) bool
+}
+
+// HandleKey runs actions of the scopes bound to the key, until one of them is handled
+func HandleKey(ev *tcell.EventKey, handle func(a *Action) bool, scopes ...Scope) bool {
+	for _, scope := range scopes {
+		for _, a := range Bound(scope) {
+			if a.Matches(ev) && handle(a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Register adds the action with default keys. It is meant to be called during package initialization
+func Register(id string, description string, scope Scope, keys ...string) *Action {
+	if _, ok := byId[id]; ok {
+		panic("action " + id + " is registered twice")
+	}
+	a := &Action{
+		Id:          id,
+		Description: description,
+		Scope:       scope,
+	}
+	for _, str := range keys {
+		key, err := ParseKey(str)
+		if err != nil {
+			panic(err)
+		}
+		a.defaults = append(a.defaults, key)
+	}
+	a.keys = a.defaults
+	registry = append(registry, a)
+	byId[id] = a
+	return a
+}
+
+// Get returns the action by id
+func Get(id string) *Action {
+	return byId[id]
+}
+
+// All returns registered actions in order of registration
+func All() []*Action {
+	return registry
+}
+
+// Bound returns actions of the scope which have keys
+func Bound(scope Scope) []*Action {
+	var bound []*Action
+	for _, a := range registry {
+		if a.Scope == scope && len(a.keys) > 0 {
+			bound = append(bound, a)
+		}
+	}
+	return bound
+}
+
+// Presets are keys added to defaults, like vim-style navigation
+var Presets = map[string]map[string][]string{
+	"vim": {
+		"list.down":     {"j"},
+		"list.up":       {"k"},
+		"list.home":     {"g"},
+		"list.end":      {"G"},
+		"list.pageDown": {"Ctrl+F"},
+		"list.pageUp":   {"Ctrl+B"},
+	},
+}
+
+// Configure restores default keys, adds keys of the preset and then applies bindings. Bindings replace keys
+// of the action, and empty list unbinds it
+func Configure(preset string, bindings map[string][]string) error {
+	for _, a := range registry {
+		a.keys = a.defaults
+	}
+	if preset != "" {
+		keys, ok := Presets[preset]
+		if !ok {
+			return fmt.Errorf("unknown key preset %q", preset)
+		}
+		for id, names := range keys {
+			a, parsed, err := parseBinding(id, names)
+			if err != nil {
+				return err
+			}
+			a.keys = append(append([]Key{}, a.keys...), parsed...)
+		}
+	}
+	for id, names := range bindings {
+		a, parsed, err := parseBinding(id, names)
+		if err != nil {
+			return err
+		}
+		a.keys = parsed
+	}
+	return checkConflicts()
+}
+
+func parseBinding(id string, names []string) (*Action, []Key, error) {
+	a := byId[id]
+	if a == nil {
+		return nil, nil, fmt.Errorf("unknown action %q", id)
+	}
+	var keys []Key
+	for _, name := range names {
+		key, err := ParseKey(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", id, err)
+		}
+		keys = append(keys, key)
+	}
+	return a, keys, nil
+}
+
+// combinations returns sets of scopes which are active at the same time, as ScopesOf returns them for the menu,
+// popups and resource lists of every kind. Theme editor takes all keys over while it is on, so its scope only
+// works along with the global one
+func combinations() [][]Scope {
+	combos := [][]Scope{
+		{Menu, List, Global},
+		{Popup, List, Global},
+		{Resource, Marks, List, Global},
+		{Theme, Global},
+	}
+	for _, scope := range Scopes() {
+		if scope.isKind() {
+			combos = append(combos, []Scope{Resource, scope, Marks, List, Global})
+		}
+	}
+	return combos
+}
+
+func (a *Action) hasDefault(key Key) bool {
+	for _, k := range a.defaults {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConflicts makes sure a key isn't bound to several actions which are active at the same time. Keys shared
+// by default bindings are fine, the focused widget handles them before global ones
+func checkConflicts() error {
+	seen := make(map[string]bool)
+	var conflicts []string
+	for _, scopes := range combinations() {
+		bound := make(map[Key]*Action)
+		for _, scope := range scopes {
+			for _, a := range registry {
+				if a.Scope != scope {
+					continue
+				}
+				for _, key := range a.keys {
+					other, ok := bound[key]
+					if !ok {
+						bound[key] = a
+						continue
+					}
+					if other == a || other.hasDefault(key) && a.hasDefault(key) {
+						continue
+					}
+					conflict := fmt.Sprintf("%s is bound to both %s and %s", key, other.Id, a.Id)
+					if !seen[conflict] {
+						seen[conflict] = true
+						conflicts = append(conflicts, conflict)
+					}
+				}
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("key conflicts: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// Hint describes the first key of the action for hint lines, like "Enter: apply". Unbound action has no hint
+func (a *Action) Hint(text string) string {
+	if len(a.keys) == 0 {
+		return ""
+	}
+	return a.keys[0].String() + ": " + text
+}
+
+// Hints joins hints into a hint line
+func Hints(hints ...string) string {
+	var line []string
+	for _, hint := range hints {
+		if hint != "" {
+			line = append(line, hint)
+		}
+	}
+	return strings.Join(line, "  ")
+}
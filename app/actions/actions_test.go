@@ -0,0 +1,60 @@
+package actions
+
+import (
+	"fmt"
+	"testing"
+)
+
+func init() {
+	Register("test.back", "Go back", Global, "Esc")
+	Register("test.clear", "Clear filter", List, "Esc")
+	Register("test.down", "Select next row", List, "Down", "x")
+	Register("test.menu", "Open", Menu, "Enter")
+	Register("test.logs", "Show logs", Kind("TestKind"), "l")
+	Register("test.bold", "Switch bold", Theme, "b")
+}
+
+func TestConfigureConflicts(t *testing.T) {
+	defer func() {
+		_ = Configure("", nil)
+	}()
+	for _, c := range []struct {
+		name     string
+		bindings map[string][]string
+		conflict bool
+	}{
+		{"defaults share keys on purpose", nil, false},
+		{"same scope", map[string][]string{"test.clear": {"x"}}, true},
+		{"kind and list", map[string][]string{"test.logs": {"x"}}, true},
+		{"kind and global", map[string][]string{"test.logs": {"Esc"}}, true},
+		{"menu and list", map[string][]string{"test.menu": {"Down"}}, true},
+		{"menu and kind", map[string][]string{"test.menu": {"l"}}, false},
+		{"theme and list", map[string][]string{"test.bold": {"Down"}}, false},
+		{"theme and global", map[string][]string{"test.bold": {"Esc"}}, true},
+	} {
+		err := Configure("", c.bindings)
+		if c.conflict && err == nil {
+			t.Errorf("%s: conflict isn't reported", c.name)
+		}
+		if !c.conflict && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	back, clear := Get("test.back"), Get("test.clear")
+	ev := back.Keys()[0].Event()
+	var tried []string
+	handled := HandleKey(ev, func(a *Action) bool {
+		tried = append(tried, a.Id)
+		return a == back
+	}, List, Global)
+	expected := []string{clear.Id, back.Id}
+	if !handled || fmt.Sprint(tried) != fmt.Sprint(expected) {
+		t.Errorf("actions %v are tried, expected %v", tried, expected)
+	}
+	if HandleKey(ev, func(a *Action) bool { return true }, Menu) {
+		t.Errorf("key is handled by scope it isn't bound in")
+	}
+}
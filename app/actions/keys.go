@@ -0,0 +1,149 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key is a key combination bound to an action
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+	Mod  tcell.ModMask
+}
+
+// Key names in addition to tcell ones
+var keyAliases = map[string]tcell.Key{
+	"escape":    tcell.KeyEsc,
+	"del":       tcell.KeyDelete,
+	"ins":       tcell.KeyInsert,
+	"return":    tcell.KeyEnter,
+	"pageup":    tcell.KeyPgUp,
+	"pagedown":  tcell.KeyPgDn,
+	"backspace": tcell.KeyBackspace2,
+}
+
+var keyNames = func() map[string]tcell.Key {
+	names := make(map[string]tcell.Key)
+	for key, name := range tcell.KeyNames {
+		if !strings.HasPrefix(name, "Ctrl-") {
+			names[strings.ToLower(name)] = key
+		}
+	}
+	for name, key := range keyAliases {
+		names[name] = key
+	}
+	return names
+}()
+
+// isControl reports whether the key is a control character. Such keys are pressed with Ctrl, so the modifier
+// is a part of the key itself
+func isControl(key tcell.Key) bool {
+	return key < tcell.KeyRune || key == tcell.KeyDEL
+}
+
+// ParseKey parses keys like "d", "Shift+L", "Ctrl+N", "F2", "Alt+Enter" or "Space"
+func ParseKey(str string) (Key, error) {
+	if utf8.RuneCountInString(str) == 1 {
+		r, _ := utf8.DecodeRuneInString(str)
+		return Key{Key: tcell.KeyRune, Rune: r}, nil
+	}
+	name := str
+	var mods []string
+	if i := strings.LastIndex(str[:len(str)-1], "+"); i != -1 {
+		name = str[i+1:]
+		mods = strings.Split(str[:i], "+")
+	}
+	var mod tcell.ModMask
+	for _, m := range mods {
+		switch strings.ToLower(m) {
+		case "ctrl":
+			mod |= tcell.ModCtrl
+		case "alt":
+			mod |= tcell.ModAlt
+		case "shift":
+			mod |= tcell.ModShift
+		default:
+			return Key{}, fmt.Errorf("invalid key %q: unknown modifier %q", str, m)
+		}
+	}
+	if strings.ToLower(name) == "space" {
+		name = " "
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		return runeKey(str, []rune(name)[0], mod)
+	}
+	key, ok := keyNames[strings.ToLower(name)]
+	if !ok {
+		return Key{}, fmt.Errorf("invalid key %q: unknown key %q", str, name)
+	}
+	if isControl(key) {
+		mod &^= tcell.ModCtrl
+	}
+	return Key{Key: key, Mod: mod}, nil
+}
+
+func runeKey(str string, r rune, mod tcell.ModMask) (Key, error) {
+	if mod&tcell.ModShift != 0 {
+		// Shifted characters are reported as upper case runes
+		r = unicode.ToUpper(r)
+		mod &^= tcell.ModShift
+	}
+	if mod&tcell.ModCtrl == 0 {
+		return Key{Key: tcell.KeyRune, Rune: r, Mod: mod}, nil
+	}
+	mod &^= tcell.ModCtrl
+	r = unicode.ToLower(r)
+	switch {
+	case r >= 'a' && r <= 'z':
+		return Key{Key: tcell.KeyCtrlA + tcell.Key(r-'a'), Mod: mod}, nil
+	case r == ' ':
+		return Key{Key: tcell.KeyCtrlSpace, Mod: mod}, nil
+	}
+	return Key{}, fmt.Errorf("invalid key %q: Ctrl works with letters only", str)
+}
+
+// Matches reports whether the event is the key
+func (k Key) Matches(ev *tcell.EventKey) bool {
+	mod := ev.Modifiers() &^ tcell.ModMeta
+	if ev.Key() == tcell.KeyRune {
+		return k.Key == tcell.KeyRune && k.Rune == ev.Rune() && mod&^tcell.ModShift == k.Mod
+	}
+	if isControl(ev.Key()) {
+		mod &^= tcell.ModCtrl
+	}
+	return k.Key == ev.Key() && mod == k.Mod
+}
+
+// Event returns event of pressing the key
+func (k Key) Event() *tcell.EventKey {
+	return tcell.NewEventKey(k.Key, k.Rune, k.Mod)
+}
+
+func (k Key) String() string {
+	var parts []string
+	if k.Mod&tcell.ModCtrl != 0 {
+		parts = append(parts, "Ctrl")
+	}
+	if k.Mod&tcell.ModAlt != 0 {
+		parts = append(parts, "Alt")
+	}
+	if k.Mod&tcell.ModShift != 0 {
+		parts = append(parts, "Shift")
+	}
+	var name string
+	switch {
+	case k.Key == tcell.KeyRune && k.Rune == ' ':
+		name = "Space"
+	case k.Key == tcell.KeyRune:
+		name = string(k.Rune)
+	case k.Key == tcell.KeyBackspace2:
+		name = "Backspace"
+	default:
+		name = strings.Replace(tcell.KeyNames[k.Key], "Ctrl-", "Ctrl+", 1)
+	}
+	return strings.Join(append(parts, name), "+")
+}
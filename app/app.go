@@ -1,12 +1,16 @@
 package app
 
 import (
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/cache"
 	"github.com/AnatolyRugalev/kube-commander/app/ui"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/status"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/workspace"
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	"os"
 )
 
 type app struct {
@@ -16,6 +20,9 @@ type app struct {
 	config           commander.Config
 	client           commander.Client
 	resourceProvider commander.ResourceProvider
+	resourceCache    commander.ResourceCache
+	stateStore       commander.StateStore
+	settingsStore    commander.SettingsStore
 	commandBuilder   commander.CommandBuilder
 	commandExecutor  commander.CommandExecutor
 	screen           commander.Screen
@@ -23,6 +30,9 @@ type app struct {
 	status           commander.StatusReporter
 
 	defaultNamespace string
+	// Context and namespace to start with after the app stops
+	nextContext   string
+	nextNamespace string
 
 	quit chan struct{}
 }
@@ -31,11 +41,24 @@ func (a app) Quit() {
 	close(a.quit)
 }
 
-func NewApp(config commander.Config, client commander.Client, resourceProvider commander.ResourceProvider, commandBuilder commander.CommandBuilder, commandExecutor commander.CommandExecutor, defaultNamespace string) *app {
+func (a *app) SwitchContext(context string, namespace string) {
+	a.nextContext = context
+	a.nextNamespace = namespace
+	a.Quit()
+}
+
+// NextContext returns context and namespace to switch to after Run returns. Empty context means the app has quit
+func (a *app) NextContext() (string, string) {
+	return a.nextContext, a.nextNamespace
+}
+
+func NewApp(config commander.Config, client commander.Client, resourceProvider commander.ResourceProvider, commandBuilder commander.CommandBuilder, commandExecutor commander.CommandExecutor, stateStore commander.StateStore, settingsStore commander.SettingsStore, defaultNamespace string) *app {
 	a := app{
 		config:           config,
 		client:           client,
 		resourceProvider: resourceProvider,
+		stateStore:       stateStore,
+		settingsStore:    settingsStore,
 		commandBuilder:   commandBuilder,
 		commandExecutor:  commandExecutor,
 		defaultNamespace: defaultNamespace,
@@ -43,6 +66,9 @@ func NewApp(config commander.Config, client commander.Client, resourceProvider c
 		quit: make(chan struct{}),
 	}
 	a.commandExecutor = NewAppExecutor(&a, commandExecutor)
+	a.resourceCache = cache.NewCache(client, settingsStore.Settings().Columns, func(err error) {
+		a.StatusReporter().Error(err)
+	})
 	return &a
 }
 
@@ -58,6 +84,18 @@ func (a app) ResourceProvider() commander.ResourceProvider {
 	return a.resourceProvider
 }
 
+func (a app) ResourceCache() commander.ResourceCache {
+	return a.resourceCache
+}
+
+func (a app) StateStore() commander.StateStore {
+	return a.stateStore
+}
+
+func (a app) SettingsStore() commander.SettingsStore {
+	return a.settingsStore
+}
+
 func (a app) CommandBuilder() commander.CommandBuilder {
 	return a.commandBuilder
 }
@@ -74,6 +112,10 @@ func (a app) Update() {
 	a.tApp.Update()
 }
 
+func (a app) PostFunc(f func()) {
+	a.tApp.PostFunc(f)
+}
+
 func (a app) CurrentNamespace() string {
 	return a.defaultNamespace
 }
@@ -90,6 +132,9 @@ func (a *app) initScreen() (err error) {
 	}
 	a.tApp.SetScreen(a.tScreen)
 	a.tApp.SetRootWidget(a.screen)
+	if mouse.Enabled {
+		a.tScreen.EnableMouse()
+	}
 	return
 }
 
@@ -126,5 +171,15 @@ func (a *app) Run() error {
 	<-a.quit
 
 	a.tApp.Quit()
-	return a.tApp.Wait()
+	if err := a.tApp.Wait(); err != nil {
+		return err
+	}
+	// Session is nice to have, so failing to save it doesn't fail the app
+	if err := a.workspace.SaveSession(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unable to save session: %s\n", err.Error())
+	}
+	// Nothing of this app is watched anymore, even when the app starts over in another context
+	a.workspace.OnHide()
+	a.resourceCache.Stop()
+	return nil
 }
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/columns"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sync"
+)
+
+type ErrorFunc func(err error)
+
+type key struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	selector  commander.Selector
+}
+
+// cache deduplicates lists and watches of the same resources. Every unique combination of resource,
+// namespace and selector is served by a single informer which lives while it has at least one subscriber
+type cache struct {
+	sync.Mutex
+
+	client    commander.Client
+	onError   ErrorFunc
+	informers map[key]*informer
+
+	// Custom columns by group kind, as they are written in settings and compiled
+	custom   map[string][]commander.CustomColumn
+	compiled map[schema.GroupKind][]columns.Column
+}
+
+func NewCache(client commander.Client, custom map[string][]commander.CustomColumn, onError ErrorFunc) *cache {
+	return &cache{
+		client:    client,
+		onError:   onError,
+		informers: make(map[key]*informer),
+		custom:    custom,
+		compiled:  make(map[schema.GroupKind][]columns.Column),
+	}
+}
+
+// customColumns compiles custom columns of the group kind. Invalid columns are reported and skipped
+func (c *cache) customColumns(gk schema.GroupKind) []columns.Column {
+	if cols, ok := c.compiled[gk]; ok {
+		return cols
+	}
+	var cols []columns.Column
+	for _, custom := range c.custom[gk.String()] {
+		column, err := columns.NewCustom(custom)
+		if err != nil {
+			c.onError(err)
+			continue
+		}
+		cols = append(cols, column)
+	}
+	c.compiled[gk] = cols
+	return cols
+}
+
+func newKey(resource *commander.Resource, namespace string, selector commander.Selector) key {
+	if !resource.Namespaced {
+		namespace = ""
+	}
+	return key{
+		gvr:       resource.GroupVersionResource(),
+		namespace: namespace,
+		selector:  selector,
+	}
+}
+
+func (c *cache) Subscribe(resource *commander.Resource, namespace string, selector commander.Selector) commander.Subscription {
+	k := newKey(resource, namespace, selector)
+	c.Lock()
+	defer c.Unlock()
+	inf, ok := c.informers[k]
+	if !ok {
+		inf = newInformer(c.client, resource, k.namespace, selector, c.customColumns(resource.Gk), c.onError, func(inf *informer) {
+			c.Lock()
+			defer c.Unlock()
+			c.forget(k, inf)
+		})
+		c.informers[k] = inf
+	}
+	sub := newSubscription(func(s *subscription) {
+		c.unsubscribe(k, inf, s)
+	})
+	inf.subscribe(sub)
+	return sub
+}
+
+func (c *cache) unsubscribe(k key, inf *informer, sub *subscription) {
+	c.Lock()
+	defer c.Unlock()
+	if inf.unsubscribe(sub) == 0 {
+		inf.stop()
+		c.forget(k, inf)
+	}
+}
+
+// Refresh detaches the informer from the cache, so next subscriptions list resources again.
+// Current subscribers are served by the detached informer until they unsubscribe
+func (c *cache) Refresh(resource *commander.Resource, namespace string, selector commander.Selector) {
+	k := newKey(resource, namespace, selector)
+	c.Lock()
+	defer c.Unlock()
+	if inf, ok := c.informers[k]; ok {
+		c.forget(k, inf)
+	}
+}
+
+// forget detaches the informer from the cache. Must be called with the lock held
+func (c *cache) forget(k key, inf *informer) {
+	if c.informers[k] == inf {
+		delete(c.informers, k)
+	}
+}
+
+// Stop stops every informer, so nothing is watched once the app quits
+func (c *cache) Stop() {
+	c.Lock()
+	defer c.Unlock()
+	for k, inf := range c.informers {
+		inf.stop()
+		delete(c.informers, k)
+	}
+}
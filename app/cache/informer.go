@@ -0,0 +1,473 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/columns"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Number of items to fetch per list request
+	listChunkSize = 500
+	// How many times listing could start over when continue token expires
+	listMaxRestarts = 3
+	// Number of failed reconnection attempts after which the data is considered stale
+	watchStaleFailures = 3
+	// Watch which stayed open for this long is considered healthy even if it didn't deliver any events
+	watchHealthyDuration = time.Second * 10
+)
+
+var errStopped = errors.New("informer stopped")
+
+func newWatchBackoff() *wait.Backoff {
+	return &wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.5,
+		Steps:    math.MaxInt32,
+		Cap:      time.Second * 30,
+	}
+}
+
+// informer lists and watches resources, keeps track of known rows and fans operations out to subscribers
+type informer struct {
+	sync.Mutex
+
+	client    commander.Client
+	resource  *commander.Resource
+	namespace string
+	selector  commander.Selector
+	custom    []columns.Column
+	onError   ErrorFunc
+	// onFail is called when initial loading fails, so the informer isn't shared anymore and gets retried
+	onFail func(i *informer)
+
+	subscribers map[*subscription]struct{}
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+	started     bool
+
+	// Known state, which is modified only by publish
+	loading     bool
+	loaded      int
+	total       int
+	columns     []string
+	definitions []metav1.TableColumnDefinition
+	rows        map[string]commander.Row
+	watchState  commander.WatchState
+}
+
+func newInformer(client commander.Client, resource *commander.Resource, namespace string, selector commander.Selector, custom []columns.Column, onError ErrorFunc, onFail func(i *informer)) *informer {
+	return &informer{
+		client:      client,
+		resource:    resource,
+		namespace:   namespace,
+		selector:    selector,
+		custom:      custom,
+		onError:     onError,
+		onFail:      onFail,
+		subscribers: make(map[*subscription]struct{}),
+		stopCh:      make(chan struct{}),
+		rows:        make(map[string]commander.Row),
+	}
+}
+
+func (i *informer) subscribe(sub *subscription) {
+	i.Lock()
+	defer i.Unlock()
+	i.subscribers[sub] = struct{}{}
+	if !i.started {
+		i.started = true
+		go i.run()
+		return
+	}
+	sub.push(i.snapshot())
+}
+
+// unsubscribe returns the number of remaining subscribers
+func (i *informer) unsubscribe(sub *subscription) int {
+	i.Lock()
+	defer i.Unlock()
+	delete(i.subscribers, sub)
+	return len(i.subscribers)
+}
+
+// stop stops the informer. It is stopped either by its last subscriber or by the cache, whichever is first
+func (i *informer) stop() {
+	i.stopOnce.Do(func() {
+		close(i.stopCh)
+	})
+}
+
+// snapshot renders known state as operations for a new subscriber
+func (i *informer) snapshot() []commander.Operation {
+	ops := []commander.Operation{
+		&commander.OpInitStart{},
+		&commander.OpClear{},
+	}
+	if i.columns != nil {
+		ops = append(ops, &commander.OpSetColumns{Columns: i.columns, Definitions: i.definitions})
+	}
+	ids := make([]string, 0, len(i.rows))
+	for id := range i.rows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		ops = append(ops, &commander.OpAdded{Row: i.rows[id]})
+	}
+	if i.loading {
+		ops = append(ops, &commander.OpInitProgress{Loaded: i.loaded, Total: i.total})
+	} else {
+		ops = append(ops, &commander.OpInitFinished{})
+	}
+	if i.watchState != commander.WatchNone {
+		ops = append(ops, &commander.OpSetWatchState{State: i.watchState})
+	}
+	return ops
+}
+
+// publish updates known state and delivers operations to every subscriber
+func (i *informer) publish(ops ...commander.Operation) {
+	i.Lock()
+	defer i.Unlock()
+	for _, operation := range ops {
+		switch op := operation.(type) {
+		case *commander.OpInitStart:
+			i.loading = true
+		case *commander.OpInitFinished:
+			i.loading = false
+		case *commander.OpInitProgress:
+			i.loaded, i.total = op.Loaded, op.Total
+		case *commander.OpClear:
+			i.rows = make(map[string]commander.Row)
+		case *commander.OpSetColumns:
+			i.columns, i.definitions = op.Columns, op.Definitions
+		case *commander.OpAdded:
+			i.rows[op.Row.Id()] = op.Row
+		case *commander.OpModified:
+			i.rows[op.Row.Id()] = op.Row
+		case *commander.OpDeleted:
+			delete(i.rows, op.RowId)
+		case *commander.OpSetWatchState:
+			i.watchState = op.State
+		}
+	}
+	for sub := range i.subscribers {
+		sub.push(ops)
+	}
+}
+
+func (i *informer) run() {
+	i.publish(&commander.OpInitStart{})
+	resourceVersion, err := i.load()
+	if err == errStopped {
+		return
+	}
+	if err != nil {
+		i.onFail(i)
+		i.publish(&commander.OpInitFinished{})
+		i.onError(err)
+		return
+	}
+	i.publish(&commander.OpInitFinished{})
+	i.watchRows(resourceVersion)
+}
+
+// watchRows keeps watch open until the informer is stopped. Interrupted watches are resumed from the last seen
+// resource version. When resource version is too old to resume from, resources are listed again
+func (i *informer) watchRows(resourceVersion string) {
+	backoff := newWatchBackoff()
+	failures := 0
+	relist := false
+	for {
+		var (
+			err     error
+			healthy bool
+		)
+		if relist {
+			resourceVersion, err = i.relist()
+			if err == nil {
+				relist = false
+			}
+		}
+		if err == nil {
+			resourceVersion, healthy, err = i.watch(resourceVersion)
+			if healthy {
+				backoff = newWatchBackoff()
+				failures = 0
+			}
+		}
+		if err == errStopped {
+			return
+		}
+		if err == nil && healthy {
+			// Server closed the watch gracefully, so we can resume it right away
+			continue
+		}
+		if apierrs.IsResourceExpired(err) || apierrs.IsGone(err) {
+			relist = true
+		} else if err != nil {
+			i.onError(fmt.Errorf("error while watching: %w", err))
+		}
+		failures++
+		state := commander.WatchReconnecting
+		if failures > watchStaleFailures {
+			state = commander.WatchStale
+		}
+		i.publish(&commander.OpSetWatchState{State: state})
+		select {
+		case <-i.stopCh:
+			return
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// watch streams changes starting from resourceVersion until the watch is closed. Returns the last seen resource
+// version and whether the watch was healthy: delivered any events or stayed open for a while
+func (i *informer) watch(resourceVersion string) (string, bool, error) {
+	opts := i.selector.ListOptions()
+	opts.ResourceVersion = resourceVersion
+	opts.AllowWatchBookmarks = true
+	watcher, err := i.client.WatchAsTable(context.TODO(), i.resource, i.namespace, opts, i.includeObject())
+	if err != nil {
+		return resourceVersion, false, err
+	}
+	defer watcher.Stop()
+	i.publish(&commander.OpSetWatchState{State: commander.WatchConnected})
+
+	healthy := false
+	started := time.Now()
+	for {
+		select {
+		case <-i.stopCh:
+			return resourceVersion, healthy, errStopped
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion, healthy || time.Since(started) > watchHealthyDuration, nil
+			}
+			if event.Type == watch.Error {
+				return resourceVersion, healthy, apierrs.FromObject(event.Object)
+			}
+			healthy = true
+			if event.Type == watch.Bookmark {
+				if rv := bookmarkVersion(event); rv != "" {
+					resourceVersion = rv
+				}
+				continue
+			}
+			rows, err := i.extractRows(event)
+			if err != nil {
+				return resourceVersion, healthy, err
+			}
+			var ops []commander.Operation
+			for _, row := range rows {
+				switch event.Type {
+				case watch.Added:
+					ops = append(ops, &commander.OpAdded{Row: row, SortById: true})
+				case watch.Modified:
+					ops = append(ops, &commander.OpModified{Row: row})
+				case watch.Deleted:
+					ops = append(ops, &commander.OpDeleted{RowId: row.Id()})
+				}
+				resourceVersion = row.Metadata().ResourceVersion
+			}
+			if len(ops) > 0 {
+				i.publish(ops...)
+			}
+		}
+	}
+}
+
+// bookmarkVersion extracts resource version from bookmark event
+func bookmarkVersion(event watch.Event) string {
+	table, ok := event.Object.(*metav1.Table)
+	if !ok {
+		return ""
+	}
+	if table.ResourceVersion != "" {
+		return table.ResourceVersion
+	}
+	for _, row := range table.Rows {
+		md := metav1.PartialObjectMetadata{}
+		err := runtime.DecodeInto(unstructured.UnstructuredJSONScheme, row.Object.Raw, &md)
+		if err == nil && md.ResourceVersion != "" {
+			return md.ResourceVersion
+		}
+	}
+	return ""
+}
+
+func (i *informer) extractRows(event watch.Event) ([]*commander.KubernetesRow, error) {
+	table, ok := event.Object.(*metav1.Table)
+	if !ok {
+		return nil, fmt.Errorf("unexpected watch object %T", event.Object)
+	}
+	var rows []*commander.KubernetesRow
+	for _, row := range table.Rows {
+		k8sRow, err := i.newRow(row)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, k8sRow)
+	}
+	return rows, nil
+}
+
+type chunkFunc func(table *metav1.Table, rows []commander.Row, first bool)
+
+// list lists resources in chunks and calls chunkFunc as soon as each chunk arrives.
+// Returns resource version of the whole list to start watching from
+func (i *informer) list(f chunkFunc) (string, error) {
+	opts := i.selector.ListOptions()
+	opts.Limit = listChunkSize
+	restarts := 0
+	for {
+		select {
+		case <-i.stopCh:
+			return "", errStopped
+		default:
+		}
+		table, err := i.client.ListAsTable(context.TODO(), i.resource, i.namespace, opts, i.includeObject())
+		if err != nil {
+			if apierrs.IsResourceExpired(err) && opts.Continue != "" && restarts < listMaxRestarts {
+				// Continue token has expired, so we have to start over
+				restarts++
+				opts.Continue = ""
+				continue
+			}
+			return "", err
+		}
+		var rows []commander.Row
+		for _, row := range table.Rows {
+			k8sRow, err := i.newRow(row)
+			if err != nil {
+				return "", err
+			}
+			rows = append(rows, k8sRow)
+		}
+		f(table, rows, opts.Continue == "")
+		if table.Continue == "" {
+			return table.ResourceVersion, nil
+		}
+		opts.Continue = table.Continue
+	}
+}
+
+// load publishes rows as soon as each chunk of the list arrives
+func (i *informer) load() (string, error) {
+	loaded := 0
+	return i.list(func(table *metav1.Table, rows []commander.Row, first bool) {
+		var ops []commander.Operation
+		if first {
+			loaded = 0
+			ops = append(ops,
+				&commander.OpClear{},
+				&commander.OpSetColumns{Columns: i.columnNames(table), Definitions: i.columnDefinitions(table)},
+			)
+		}
+		for _, row := range rows {
+			ops = append(ops, &commander.OpAdded{Row: row})
+		}
+		loaded += len(rows)
+		total := loaded
+		if table.RemainingItemCount != nil {
+			total += int(*table.RemainingItemCount)
+		}
+		ops = append(ops, &commander.OpInitProgress{Loaded: loaded, Total: total})
+		i.publish(ops...)
+	})
+}
+
+// relist lists resources again and publishes the difference with known rows
+func (i *informer) relist() (string, error) {
+	var columns *commander.OpSetColumns
+	fresh := make(map[string]commander.Row)
+	resourceVersion, err := i.list(func(table *metav1.Table, rows []commander.Row, first bool) {
+		if first {
+			columns = &commander.OpSetColumns{Columns: i.columnNames(table), Definitions: i.columnDefinitions(table)}
+			fresh = make(map[string]commander.Row)
+		}
+		for _, row := range rows {
+			fresh[row.Id()] = row
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	// Only publish modifies known rows, so it is safe to read them here
+	ops := []commander.Operation{columns}
+	for id := range i.rows {
+		if _, ok := fresh[id]; !ok {
+			ops = append(ops, &commander.OpDeleted{RowId: id})
+		}
+	}
+	for id, row := range fresh {
+		if _, ok := i.rows[id]; ok {
+			ops = append(ops, &commander.OpModified{Row: row})
+		} else {
+			ops = append(ops, &commander.OpAdded{Row: row, SortById: true})
+		}
+	}
+	i.publish(ops...)
+	return resourceVersion, nil
+}
+
+func (i *informer) columnNames(table *metav1.Table) []string {
+	var names []string
+	for _, col := range i.columnDefinitions(table) {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// columnDefinitions returns column definitions of the table followed by custom columns
+func (i *informer) columnDefinitions(table *metav1.Table) []metav1.TableColumnDefinition {
+	if len(i.custom) == 0 {
+		return table.ColumnDefinitions
+	}
+	definitions := append([]metav1.TableColumnDefinition{}, table.ColumnDefinitions...)
+	for _, column := range i.custom {
+		definitions = append(definitions, column.Definition)
+	}
+	return definitions
+}
+
+// includeObject asks for full objects when there are custom columns to evaluate
+func (i *informer) includeObject() metav1.IncludeObjectPolicy {
+	if len(i.custom) == 0 {
+		return ""
+	}
+	return metav1.IncludeObject
+}
+
+// newRow creates a row with values of custom columns appended to the table cells
+func (i *informer) newRow(row metav1.TableRow) (*commander.KubernetesRow, error) {
+	if len(i.custom) > 0 {
+		obj := &unstructured.Unstructured{}
+		_, _, err := unstructured.UnstructuredJSONScheme.Decode(row.Object.Raw, nil, obj)
+		if err != nil {
+			return nil, err
+		}
+		cells := make([]interface{}, 0, len(row.Cells)+len(i.custom))
+		cells = append(cells, row.Cells...)
+		for _, column := range i.custom {
+			cells = append(cells, column.Cell(obj))
+		}
+		row.Cells = cells
+	}
+	return commander.NewKubernetesRow(row)
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"sync"
+)
+
+// subscription queues operations, so a slow subscriber never blocks the informer and other subscribers
+type subscription struct {
+	sync.Mutex
+
+	provider commander.RowProvider
+	queue    []commander.Operation
+	notify   chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	onClose  func(s *subscription)
+}
+
+func newSubscription(onClose func(s *subscription)) *subscription {
+	s := &subscription{
+		provider: make(commander.RowProvider),
+		notify:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		onClose:  onClose,
+	}
+	go s.run()
+	return s
+}
+
+func (s *subscription) Provider() commander.RowProvider {
+	return s.provider
+}
+
+func (s *subscription) Close() {
+	s.once.Do(func() {
+		s.onClose(s)
+		close(s.done)
+	})
+}
+
+func (s *subscription) push(ops []commander.Operation) {
+	s.Lock()
+	s.queue = append(s.queue, ops...)
+	s.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued operations. Everything queued since the last delivery is delivered as a single batch
+func (s *subscription) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.notify:
+		}
+		s.Lock()
+		ops := s.queue
+		s.queue = nil
+		s.Unlock()
+		if len(ops) == 0 {
+			continue
+		}
+		select {
+		case s.provider <- ops:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -3,17 +3,24 @@ package client
 import (
 	"context"
 	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/columns"
 	"github.com/AnatolyRugalev/kube-commander/commander"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	restclientwatch "k8s.io/client-go/rest/watch"
 	"k8s.io/kubectl/pkg/scheme"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -43,6 +50,10 @@ func NewClient(config commander.Config) (*client, error) {
 		config:     config,
 		restConfig: c,
 		restClient: r,
+		printerCache: &printerColumnsCache{
+			columns: make(map[schema.GroupVersionResource][]columns.Column),
+		},
+		resourcesCache: &resourcesCache{},
 	}
 	return cl, nil
 }
@@ -53,6 +64,13 @@ type client struct {
 	restClient *rest.RESTClient
 	timeout    time.Duration
 
+	printerCache   *printerColumnsCache
+	resourcesCache *resourcesCache
+}
+
+// resourcesCache keeps discovered resources, so menu and command line don't query the server every time
+type resourcesCache struct {
+	sync.Mutex
 	resources commander.ResourceMap
 }
 
@@ -71,6 +89,22 @@ func (c client) Delete(ctx context.Context, resource *commander.Resource, namesp
 	return res.Error()
 }
 
+func (c client) Patch(ctx context.Context, resource *commander.Resource, namespace string, name string, patchType types.PatchType, data []byte) error {
+	req, err := c.NewRequest(resource)
+	if err != nil {
+		return err
+	}
+	req.
+		Verb("PATCH").
+		Name(name).
+		SetHeader("Content-Type", string(patchType)).
+		Body(data)
+	if resource.Namespaced {
+		req.Namespace(namespace)
+	}
+	return req.Do(ctx).Error()
+}
+
 func (c client) NewRequest(resource *commander.Resource) (*rest.Request, error) {
 	restClient, err := c.rest(resource.GroupVersion())
 	if err != nil {
@@ -87,7 +121,9 @@ func (c client) NewRequest(resource *commander.Resource) (*rest.Request, error)
 }
 
 func (c client) Resources() (commander.ResourceMap, error) {
-	if c.resources == nil {
+	c.resourcesCache.Lock()
+	defer c.resourcesCache.Unlock()
+	if c.resourcesCache.resources == nil {
 		lists, err := discovery.NewDiscoveryClient(c.restClient).ServerPreferredResources()
 		if err != nil {
 			return nil, err
@@ -105,14 +141,15 @@ func (c client) Resources() (commander.ResourceMap, error) {
 				resources[gk] = &commander.Resource{
 					Namespaced: res.Namespaced,
 					Resource:   res.Name,
+					ShortNames: res.ShortNames,
 					Gk:         gk,
 					Gvk:        schema.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: res.Kind},
 				}
 			}
 		}
-		c.resources = resources
+		c.resourcesCache.resources = resources
 	}
-	return c.resources, nil
+	return c.resourcesCache.resources, nil
 }
 
 func (c client) Get(ctx context.Context, resource *commander.Resource, namespace string, name string, out runtime.Object) error {
@@ -135,23 +172,35 @@ func (c client) Get(ctx context.Context, resource *commander.Resource, namespace
 	return nil
 }
 
-func (c client) ListAsTable(ctx context.Context, resource *commander.Resource, namespace string) (*metav1.Table, error) {
-	table := metav1.Table{}
-	err := c.List(ctx, resource, namespace, &table)
+func (c client) ListAsTable(ctx context.Context, resource *commander.Resource, namespace string, opts metav1.ListOptions, includeObject metav1.IncludeObjectPolicy) (*metav1.Table, error) {
+	req, err := c.tableRequest(resource, namespace, opts, includeObject)
 	if err != nil {
 		return nil, err
 	}
-	return &table, nil
+	raw, err := req.Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	obj, _, err := c.newTableDecoder(ctx, resource).Decode(raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	table, ok := obj.(*metav1.Table)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response %T", obj)
+	}
+	return table, nil
 }
 
-func (c client) List(ctx context.Context, resource *commander.Resource, namespace string, out runtime.Object) error {
+func (c client) List(ctx context.Context, resource *commander.Resource, namespace string, opts metav1.ListOptions, out runtime.Object) error {
 	req, err := c.NewRequest(resource)
 	if err != nil {
 		return err
 	}
 
 	req.
-		Verb("GET")
+		Verb("GET").
+		VersionedParams(&opts, scheme.ParameterCodec)
 	switch out.(type) {
 	case *metav1.Table:
 		req.SetHeader("Accept", strings.Join([]string{
@@ -170,15 +219,38 @@ func (c client) List(ctx context.Context, resource *commander.Resource, namespac
 	return nil
 }
 
-func (c client) WatchAsTable(ctx context.Context, resource *commander.Resource, namespace string) (watch.Interface, error) {
-	opts := metav1.ListOptions{
-		Watch: true,
+func (c client) WatchAsTable(ctx context.Context, resource *commander.Resource, namespace string, opts metav1.ListOptions, includeObject metav1.IncludeObjectPolicy) (watch.Interface, error) {
+	opts.Watch = true
+	req, err := c.tableRequest(resource, namespace, opts, includeObject)
+	if err != nil {
+		return nil, err
 	}
-	req, err := c.NewRequest(resource)
+	// Watch is a long-running request, so let the server decide when to close it
+	req.Timeout(0)
+	stream, err := req.Stream(ctx)
 	if err != nil {
 		return nil, err
 	}
+	info, ok := runtime.SerializerInfoForMediaType(c.restConfig.NegotiatedSerializer.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	if !ok || info.StreamSerializer == nil {
+		_ = stream.Close()
+		return nil, fmt.Errorf("no stream serializer for %s", runtime.ContentTypeJSON)
+	}
+	frameReader := info.StreamSerializer.Framer.NewFrameReader(stream)
+	decoder := restclientwatch.NewDecoder(
+		streaming.NewDecoder(frameReader, info.StreamSerializer.Serializer),
+		c.newTableDecoder(ctx, resource),
+	)
+	return watch.NewStreamWatcher(decoder, apierrs.NewClientErrorReporter(http.StatusInternalServerError, "GET", "ClientWatchDecoding")), nil
+}
 
+// tableRequest prepares a request which asks for Table response. Servers which don't support Table format
+// respond with regular objects, which are converted by tableDecoder. Empty includeObject leaves the server default
+func (c client) tableRequest(resource *commander.Resource, namespace string, opts metav1.ListOptions, includeObject metav1.IncludeObjectPolicy) (*rest.Request, error) {
+	req, err := c.NewRequest(resource)
+	if err != nil {
+		return nil, err
+	}
 	req.
 		Verb("GET").
 		VersionedParams(&opts, scheme.ParameterCodec).
@@ -187,10 +259,13 @@ func (c client) WatchAsTable(ctx context.Context, resource *commander.Resource,
 			fmt.Sprintf("application/json;as=Table;v=%s;g=%s", metav1beta1.SchemeGroupVersion.Version, metav1beta1.GroupName),
 			"application/json",
 		}, ","))
+	if includeObject != "" {
+		req.Param("includeObject", string(includeObject))
+	}
 	if resource.Namespaced {
 		req.Namespace(namespace)
 	}
-	return req.Watch(ctx)
+	return req, nil
 }
 
 func (c client) rest(gv schema.GroupVersion) (*rest.RESTClient, error) {
@@ -5,6 +5,7 @@ import (
 	"k8s.io/client-go/rest"
 	cmd "k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sort"
 )
 
 type defaultConfig struct {
@@ -33,6 +34,19 @@ func NewDefaultConfig(kubeconfig string, context string, namespace string) *defa
 	}
 }
 
+func (d *defaultConfig) Contexts() ([]string, error) {
+	config, err := cmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	var names []string
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 func (d *defaultConfig) ClientConfig() (*rest.Config, error) {
 	rules := cmd.NewDefaultClientConfigLoadingRules()
 	config, err := rules.Load()
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/columns"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	serializerjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"strings"
+	"sync"
+)
+
+var crdResource = &commander.Resource{
+	Resource: "customresourcedefinitions",
+	Gk:       schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+	Gvk:      schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"},
+}
+
+func defaultPrinterColumns(resource *commander.Resource) []columns.Column {
+	cols := []columns.Column{
+		{
+			Definition: metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name"},
+			Cell: func(obj *unstructured.Unstructured) string {
+				return obj.GetName()
+			},
+		},
+	}
+	if resource.Namespaced {
+		cols = append(cols, columns.Column{
+			Definition: metav1.TableColumnDefinition{Name: "Namespace", Type: "string"},
+			Cell: func(obj *unstructured.Unstructured) string {
+				return obj.GetNamespace()
+			},
+		})
+	}
+	return append(cols, columns.Column{
+		Definition: metav1.TableColumnDefinition{Name: "Age", Type: "date"},
+		Cell: func(obj *unstructured.Unstructured) string {
+			return columns.RenderDate(obj.GetCreationTimestamp().Time)
+		},
+	})
+}
+
+// printerColumnsCache keeps printer columns resolved for resources
+type printerColumnsCache struct {
+	sync.Mutex
+	columns map[schema.GroupVersionResource][]columns.Column
+}
+
+// printerColumns returns default columns and additional printer columns of custom resources
+func (c client) printerColumns(ctx context.Context, resource *commander.Resource) []columns.Column {
+	gvr := resource.GroupVersionResource()
+	c.printerCache.Lock()
+	cols, ok := c.printerCache.columns[gvr]
+	c.printerCache.Unlock()
+	if ok {
+		return cols
+	}
+	cols = defaultPrinterColumns(resource)
+	// Only custom resources could have additional printer columns
+	if strings.Contains(resource.Gk.Group, ".") {
+		additional, err := c.additionalPrinterColumns(ctx, resource)
+		if err == nil {
+			cols = append(cols, additional...)
+		}
+	}
+	// Lock isn't held while the CRD is fetched, so slow API doesn't block decoding of other resources.
+	// Columns fetched meanwhile by another call are the same
+	c.printerCache.Lock()
+	c.printerCache.columns[gvr] = cols
+	c.printerCache.Unlock()
+	return cols
+}
+
+func (c client) additionalPrinterColumns(ctx context.Context, resource *commander.Resource) ([]columns.Column, error) {
+	crd := unstructured.Unstructured{}
+	name := resource.Resource + "." + resource.Gk.Group
+	var err error
+	for _, version := range []string{"v1", "v1beta1"} {
+		res := *crdResource
+		res.Gvk.Version = version
+		err = c.Get(ctx, &res, "", name, &crd)
+		if !apierrs.IsNotFound(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// apiextensions.k8s.io/v1 defines columns per version, v1beta1 could define them for the whole resource
+	specs, _, _ := unstructured.NestedSlice(crd.Object, "spec", "additionalPrinterColumns")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok || version["name"] != resource.Gvk.Version {
+			continue
+		}
+		if versionSpecs, ok, _ := unstructured.NestedSlice(version, "additionalPrinterColumns"); ok {
+			specs = versionSpecs
+		}
+	}
+	var cols []columns.Column
+	for _, s := range specs {
+		spec, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, _, _ := unstructured.NestedString(spec, "jsonPath")
+		if path == "" {
+			path, _, _ = unstructured.NestedString(spec, "JSONPath")
+		}
+		if path == ".metadata.creationTimestamp" {
+			// Age is among default columns already
+			continue
+		}
+		definition := metav1.TableColumnDefinition{}
+		definition.Name, _, _ = unstructured.NestedString(spec, "name")
+		definition.Type, _, _ = unstructured.NestedString(spec, "type")
+		definition.Format, _, _ = unstructured.NestedString(spec, "format")
+		definition.Description, _, _ = unstructured.NestedString(spec, "description")
+		priority, _, _ := unstructured.NestedInt64(spec, "priority")
+		definition.Priority = int32(priority)
+		column, err := columns.NewJSONPath(definition, path)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, column)
+	}
+	return cols, nil
+}
+
+// tableDecoder decodes Table responses as they are and converts any other objects into Table on the client side.
+// This allows to show resources of APIs which don't support Table response format
+type tableDecoder struct {
+	columns func() []columns.Column
+}
+
+func (c client) newTableDecoder(ctx context.Context, resource *commander.Resource) *tableDecoder {
+	var (
+		once sync.Once
+		cols []columns.Column
+	)
+	return &tableDecoder{
+		columns: func() []columns.Column {
+			once.Do(func() {
+				cols = c.printerColumns(ctx, resource)
+			})
+			return cols
+		},
+	}
+}
+
+func (d *tableDecoder) Decode(data []byte, _ *schema.GroupVersionKind, _ runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	gvk, err := serializerjson.DefaultMetaFactory.Interpret(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch gvk.Kind {
+	case "Table":
+		table := &metav1.Table{}
+		return table, gvk, json.Unmarshal(data, table)
+	case "Status":
+		status := &metav1.Status{}
+		return status, gvk, json.Unmarshal(data, status)
+	}
+	obj, gvk, err := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	table := &metav1.Table{}
+	switch o := obj.(type) {
+	case *unstructured.UnstructuredList:
+		err = d.addRows(table, o.Items)
+		table.ResourceVersion = o.GetResourceVersion()
+		table.Continue = o.GetContinue()
+		table.RemainingItemCount = o.GetRemainingItemCount()
+	case *unstructured.Unstructured:
+		err = d.addRows(table, []unstructured.Unstructured{*o})
+	default:
+		err = fmt.Errorf("unexpected object %T", obj)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return table, gvk, nil
+}
+
+func (d *tableDecoder) addRows(table *metav1.Table, items []unstructured.Unstructured) error {
+	cols := d.columns()
+	for _, column := range cols {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, column.Definition)
+	}
+	for i := range items {
+		obj := &items[i]
+		raw, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		row := metav1.TableRow{
+			Object: runtime.RawExtension{Raw: bytes.TrimSpace(raw)},
+		}
+		for _, column := range cols {
+			row.Cells = append(row.Cells, column.Cell(obj))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package columns
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/util/jsonpath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const none = "<none>"
+
+// Column is a table column evaluated on the client side
+type Column struct {
+	Definition metav1.TableColumnDefinition
+	Cell       func(obj *unstructured.Unstructured) string
+}
+
+// NewJSONPath creates a column which evaluates JSONPath expression like ".spec.nodeName".
+// Values of date columns are rendered as age
+func NewJSONPath(definition metav1.TableColumnDefinition, path string) (Column, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = fmt.Sprintf("{%s}", path)
+	}
+	parser := jsonpath.New(definition.Name).AllowMissingKeys(true)
+	err := parser.Parse(path)
+	if err != nil {
+		return Column{}, fmt.Errorf("invalid column %s: %w", definition.Name, err)
+	}
+	return Column{
+		Definition: definition,
+		Cell: func(obj *unstructured.Unstructured) string {
+			results, err := parser.FindResults(obj.UnstructuredContent())
+			if err != nil || len(results) == 0 || len(results[0]) == 0 {
+				return none
+			}
+			var values []string
+			for _, result := range results[0] {
+				value := result.Interface()
+				if definition.Type == "date" {
+					if str, ok := value.(string); ok {
+						if t, err := time.Parse(time.RFC3339, str); err == nil {
+							value = RenderDate(t)
+						}
+					}
+				}
+				values = append(values, fmt.Sprint(value))
+			}
+			return strings.Join(values, ",")
+		},
+	}, nil
+}
+
+// NewTemplate creates a column which executes Go template against the object
+func NewTemplate(definition metav1.TableColumnDefinition, text string) (Column, error) {
+	tpl, err := template.New(definition.Name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return Column{}, fmt.Errorf("invalid column %s: %w", definition.Name, err)
+	}
+	return Column{
+		Definition: definition,
+		Cell: func(obj *unstructured.Unstructured) string {
+			buf := bytes.Buffer{}
+			err := tpl.Execute(&buf, obj.UnstructuredContent())
+			if err != nil {
+				return "err: " + err.Error()
+			}
+			value := strings.ReplaceAll(buf.String(), "<no value>", "")
+			if value == "" {
+				return none
+			}
+			return value
+		},
+	}, nil
+}
+
+// NewCustom creates a column defined by user
+func NewCustom(custom commander.CustomColumn) (Column, error) {
+	definition := metav1.TableColumnDefinition{
+		Name: custom.Name,
+		Type: "string",
+	}
+	switch {
+	case custom.JSONPath != "" && custom.Template != "":
+		return Column{}, fmt.Errorf("column %s has both jsonPath and template", custom.Name)
+	case custom.JSONPath != "":
+		return NewJSONPath(definition, custom.JSONPath)
+	case custom.Template != "":
+		return NewTemplate(definition, custom.Template)
+	}
+	return Column{}, fmt.Errorf("column %s has neither jsonPath nor template", custom.Name)
+}
+
+// RenderDate renders time as age, the way kubectl does
+func RenderDate(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/columns"
+	"github.com/AnatolyRugalev/kube-commander/app/files"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	yamlv3 "gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultPath returns path of the config file in user's config directory
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kube-commander", "config.yaml")
+}
+
+// Load reads settings from the file. Missing file results in default settings
+func Load(path string) (*commander.Settings, error) {
+	settings := &commander.Settings{}
+	if path == "" {
+		return settings, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.UnmarshalStrict(data, settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	for kind, cols := range settings.Columns {
+		for _, col := range cols {
+			if _, err := columns.NewCustom(col); err != nil {
+				return nil, fmt.Errorf("invalid config %s: %s: %w", path, kind, err)
+			}
+		}
+	}
+	for _, favorite := range settings.Favorites {
+		if favorite.Kind == "" {
+			return nil, fmt.Errorf("invalid config %s: favorite %q has no kind", path, favorite.Title)
+		}
+	}
+	return settings, nil
+}
+
+// store writes settings back to the config file they were loaded from.
+// Store with empty path keeps settings in memory only
+type store struct {
+	path     string
+	settings *commander.Settings
+}
+
+func NewStore(path string, settings *commander.Settings) *store {
+	return &store{
+		path:     path,
+		settings: settings,
+	}
+}
+
+func (s *store) Settings() *commander.Settings {
+	return s.settings
+}
+
+// Save writes favorites to the config file. Only the favorites section is replaced, so the rest of the file
+// is kept as the user wrote it, with comments and formatting
+func (s *store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+	var doc yamlv3.Node
+	err = yamlv3.Unmarshal(data, &doc)
+	if err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return fmt.Errorf("error saving config: %s is not a mapping", s.path)
+	}
+	favorites, err := favoritesNode(s.settings.Favorites)
+	if err != nil {
+		return err
+	}
+	setKey(root, "favorites", favorites)
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(&doc)
+	if err != nil {
+		return err
+	}
+	err = files.WriteAtomic(s.path, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+	return nil
+}
+
+// favoritesNode encodes favorites the same way settings are read, by their JSON names. No favorites result in nil
+func favoritesNode(favorites []commander.Favorite) (*yamlv3.Node, error) {
+	if len(favorites) == 0 {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(favorites)
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	err = yamlv3.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Content[0], nil
+}
+
+// setKey replaces the value of the key in the mapping, keeping comments of the key. Nil value removes the key
+func setKey(mapping *yamlv3.Node, key string, value *yamlv3.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		if value == nil {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		} else {
+			mapping.Content[i+1] = value
+		}
+		return
+	}
+	if value != nil {
+		mapping.Content = append(mapping.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: key}, value)
+	}
+}
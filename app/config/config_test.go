@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveKeepsConfig checks that saving favorites doesn't touch the rest of the config written by the user
+func TestSaveKeepsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	written := `# Columns of my own
+columns:
+  Pod:
+    - name: Node # where it runs
+      jsonPath: .spec.nodeName
+keys:
+  resource.describe: [d, Enter]
+`
+	if err := ioutil.WriteFile(path, []byte(written), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore(path, settings)
+	settings.Favorites = []commander.Favorite{{Kind: "Pod"}, {Kind: "Application.argoproj.io", Title: "Apps"}}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), written) {
+		t.Errorf("config written by the user is changed:\n%s", data)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Favorites) != 2 || loaded.Favorites[1].Title != "Apps" {
+		t.Errorf("unexpected favorites %v", loaded.Favorites)
+	}
+	settings.Favorites = nil
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = ioutil.ReadFile(path); string(data) != written {
+		t.Errorf("favorites aren't removed:\n%s", data)
+	}
+}
@@ -1,10 +1,13 @@
 package focus
 
 import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 )
 
+var actBack = actions.Register("global.back", "Go back, close popup", actions.Global, "Esc", "Backspace")
+
 type manager struct {
 	stack []commander.Widget
 }
@@ -26,17 +29,21 @@ func (f *manager) HandleEvent(e tcell.Event, useStack bool) bool {
 			break
 		}
 	}
-	switch ev := e.(type) {
-	case *tcell.EventKey:
-		switch ev.Key() {
-		case tcell.KeyESC, tcell.KeyBackspace2:
-			f.Blur()
-			return true
-		}
+	if ev, ok := e.(*tcell.EventKey); ok && actBack.Matches(ev) {
+		return f.HandleAction(actBack)
 	}
 	return false
 }
 
+// HandleAction moves focus back
+func (f *manager) HandleAction(a *actions.Action) bool {
+	if a != actBack {
+		return false
+	}
+	f.Blur()
+	return true
+}
+
 func (f *manager) StackSize() int {
 	return len(f.stack)
 }
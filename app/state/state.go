@@ -0,0 +1,101 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/files"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"sync"
+)
+
+// DefaultPath returns path of the state file in user's config directory
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kube-commander", "state.yaml")
+}
+
+// store keeps values in a YAML file, every value under its own key.
+// Store with empty path keeps values in memory only
+type store struct {
+	sync.Mutex
+
+	path   string
+	values map[string]interface{}
+}
+
+func NewStore(path string) (*store, error) {
+	s := &store{
+		path:   path,
+		values: make(map[string]interface{}),
+	}
+	if path == "" {
+		return s, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	err = yaml.Unmarshal(data, &s.values)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing state file %s: %w", path, err)
+	}
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+	return s, nil
+}
+
+func (s *store) Load(key string, out interface{}) bool {
+	s.Lock()
+	value, ok := s.values[key]
+	s.Unlock()
+	if !ok {
+		return false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+func (s *store) Save(key string, value interface{}) error {
+	// Values are kept in their JSON form, so they are loaded the same way they were saved
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.values[key] = normalized
+	return s.write()
+}
+
+func (s *store) write() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := yaml.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	err = files.WriteAtomic(s.path, data)
+	if err != nil {
+		return fmt.Errorf("error saving state: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package border
 
 import (
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
@@ -146,6 +147,21 @@ func (b *BorderedWidget) SetView(view views.View) {
 	b.MaxSizeWidget.SetView(viewport)
 }
 
+// HandleEvent passes mouse events within borders to the widget in its coordinates. Events within borders are
+// consumed even if the widget ignores them
+func (b *BorderedWidget) HandleEvent(ev tcell.Event) bool {
+	e, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return b.MaxSizeWidget.HandleEvent(ev)
+	}
+	local, ok := mouse.Local(e, b.view)
+	if !ok {
+		return false
+	}
+	b.MaxSizeWidget.HandleEvent(local)
+	return true
+}
+
 func (b BorderedWidget) MaxSize() (int, int) {
 	w, h := b.MaxSizeWidget.MaxSize()
 	offsetW, offsetH := b.offsets()
@@ -0,0 +1,137 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"strings"
+)
+
+// Commands which aren't resource types
+const (
+	Context   = "ctx"
+	Namespace = "ns"
+	CRD       = "crd"
+)
+
+// Command is a parsed command line, like "svc -n kube-system" or "ctx prod"
+type Command struct {
+	Name string
+	Arg  string
+	// Namespace to switch to, empty keeps the current one
+	Namespace     string
+	AllNamespaces bool
+}
+
+// Parse parses command line. Namespace is set with -n, --namespace or -A, --all-namespaces like in kubectl
+func Parse(line string) (Command, error) {
+	var c Command
+	var args []string
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	for i := 0; i < len(fields); i++ {
+		switch field := fields[i]; {
+		case field == "-A" || field == "--all-namespaces":
+			c.AllNamespaces = true
+		case field == "-n" || field == "--namespace":
+			if i == len(fields)-1 {
+				return c, fmt.Errorf("%s needs a namespace", field)
+			}
+			i++
+			c.Namespace = fields[i]
+		case strings.HasPrefix(field, "--namespace="):
+			c.Namespace = strings.TrimPrefix(field, "--namespace=")
+		case strings.HasPrefix(field, "-"):
+			return c, fmt.Errorf("unknown flag %s", field)
+		default:
+			args = append(args, field)
+		}
+	}
+	switch {
+	case len(args) == 0:
+		return c, errors.New("empty command")
+	case len(args) > 2:
+		return c, fmt.Errorf("unexpected argument %s", args[2])
+	}
+	c.Name = strings.ToLower(args[0])
+	if len(args) == 2 {
+		c.Arg = args[1]
+	}
+	if c.AllNamespaces && c.Namespace != "" {
+		return c, errors.New("-n and -A can't be used together")
+	}
+	return c, nil
+}
+
+// String returns the command line the command was parsed from
+func (c Command) String() string {
+	parts := []string{c.Name}
+	if c.Arg != "" {
+		parts = append(parts, c.Arg)
+	}
+	if c.Namespace != "" {
+		parts = append(parts, "-n", c.Namespace)
+	}
+	if c.AllNamespaces {
+		parts = append(parts, "-A")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Names returns lower case names the resource type is called by: kind, plural and short names
+func Names(res *commander.Resource) []string {
+	return append([]string{strings.ToLower(res.Gk.Kind), res.Resource}, res.ShortNames...)
+}
+
+// Qualified returns the plural name with the group, like "certificates.cert-manager.io"
+func Qualified(res *commander.Resource) string {
+	if res.Gk.Group == "" {
+		return res.Resource
+	}
+	return res.Resource + "." + res.Gk.Group
+}
+
+// Resolve finds resource type by any of its names. Name could be qualified with the group to pick
+// among several groups, otherwise core group wins, and deprecated extensions group loses
+func Resolve(resources commander.ResourceMap, name string) (*commander.Resource, error) {
+	name = strings.ToLower(name)
+	var found *commander.Resource
+	for _, res := range resources {
+		if !matches(res, name) {
+			continue
+		}
+		if found == nil || less(res, found) {
+			found = res
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unknown resource type %s", name)
+	}
+	return found, nil
+}
+
+func matches(res *commander.Resource, name string) bool {
+	for _, n := range Names(res) {
+		if n == name || res.Gk.Group != "" && n+"."+res.Gk.Group == name {
+			return true
+		}
+	}
+	return false
+}
+
+func groupRank(group string) int {
+	switch group {
+	case "":
+		return 0
+	case "extensions":
+		return 2
+	}
+	return 1
+}
+
+// less orders resources of the same name, so resolving is deterministic
+func less(a, b *commander.Resource) bool {
+	if ra, rb := groupRank(a.Gk.Group), groupRank(b.Gk.Group); ra != rb {
+		return ra < rb
+	}
+	return a.Gk.Group < b.Gk.Group
+}
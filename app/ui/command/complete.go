@@ -0,0 +1,76 @@
+package command
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"sort"
+	"strings"
+)
+
+// Completer suggests words for the command line. Fields could be filled later, when they are loaded
+type Completer struct {
+	Resources  commander.ResourceMap
+	Contexts   []string
+	Namespaces []string
+}
+
+// Complete returns sorted candidates for the last word of the line
+func (c *Completer) Complete(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasSuffix(line, " ") {
+		fields = append(fields, "")
+	}
+	last := len(fields) - 1
+	var candidates []string
+	switch {
+	case last > 0 && (fields[last-1] == "-n" || fields[last-1] == "--namespace"):
+		candidates = c.Namespaces
+	case last == 0:
+		candidates = c.commands()
+	case last > 1:
+		// Commands take a single argument
+	case fields[0] == Context:
+		candidates = c.Contexts
+	case fields[0] == Namespace:
+		candidates = c.Namespaces
+	case fields[0] == CRD:
+		for _, res := range c.Resources {
+			if res.Gk.Group != "" {
+				candidates = append(candidates, Qualified(res))
+			}
+		}
+	}
+	return filter(candidates, fields[last])
+}
+
+// commands returns commands and plural and short names of resource types
+func (c *Completer) commands() []string {
+	commands := []string{Context, Namespace, CRD}
+	for _, res := range c.Resources {
+		commands = append(commands, res.Resource)
+		commands = append(commands, res.ShortNames...)
+	}
+	return commands
+}
+
+// filter returns unique candidates starting with the prefix, case-insensitive
+func filter(candidates []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	var filtered []string
+	for _, candidate := range candidates {
+		if !seen[candidate] && strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			seen[candidate] = true
+			filtered = append(filtered, candidate)
+		}
+	}
+	sort.Strings(filtered)
+	return filtered
+}
+
+// Apply replaces the last word of the line with the completion
+func Apply(line string, completion string) string {
+	if i := strings.LastIndex(line, " "); i != -1 {
+		return line[:i+1] + completion + " "
+	}
+	return completion + " "
+}
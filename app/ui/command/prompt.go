@@ -0,0 +1,240 @@
+package command
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	historyKey       = "commands"
+	historyLimit     = 50
+	completionsLimit = 8
+	promptInputWidth = 50
+)
+
+func loadHistory(store commander.StateStore) []string {
+	var history []string
+	store.Load(historyKey, &history)
+	return history
+}
+
+// addHistory puts the line on top of the history. The same line is moved rather than repeated
+func addHistory(history []string, line string) []string {
+	lines := []string{line}
+	for _, l := range history {
+		if l != line && len(lines) < historyLimit {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func promptHint() string {
+	return actions.Hints(popup.ActNext.Hint("complete"), "↑↓: history", popup.ActApply.Hint("run"))
+}
+
+// RunFunc runs the command. Error is shown in the prompt, so the command could be fixed
+type RunFunc func(cmd Command) error
+
+// prompt is a command line with completion and history
+type prompt struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view      views.View
+	input     *input.Input
+	completer *Completer
+	container commander.ResourceContainer
+	history   []string
+	// Line in history, -1 is the line being typed
+	historyIndex int
+	typed        string
+	// Line and its completions while cycling through them, completion -1 means cycling hasn't started
+	base        string
+	completions []string
+	completion  int
+	run         RunFunc
+	err         error
+
+	stCompletion commander.StyleComponent
+	stHint       commander.StyleComponent
+	stError      commander.StyleComponent
+}
+
+func newPrompt(completer *Completer, container commander.ResourceContainer, run RunFunc) *prompt {
+	p := &prompt{
+		Focusable:    focus.NewFocusable(),
+		input:        input.NewInput(":", "", promptInputWidth),
+		completer:    completer,
+		container:    container,
+		history:      loadHistory(container.StateStore()),
+		historyIndex: -1,
+		completion:   -1,
+		run:          run,
+
+		stCompletion: theme.NewComponent("completion", theme.Default),
+		stHint:       theme.NewComponent("hint", theme.Default.Underline(true)),
+		stError:      theme.NewComponent("error", theme.Default.Foreground(tcell.ColorMaroon)),
+	}
+	p.input.OnFocus()
+	return p
+}
+
+func (p *prompt) GetComponents() []commander.StyleComponent {
+	return append(p.input.GetComponents(), p.stCompletion, p.stHint, p.stError)
+}
+
+// candidates returns completions being cycled through, or completions of the typed line
+func (p *prompt) candidates() []string {
+	if p.completion != -1 {
+		return p.completions
+	}
+	return p.completer.Complete(p.input.Text())
+}
+
+func (p *prompt) Draw() {
+	p.view.Fill(' ', theme.Default)
+	p.input.Draw()
+	if p.err != nil {
+		p.drawLine(1, p.err.Error(), p.stError.Style())
+	} else {
+		p.drawLine(1, promptHint(), p.stHint.Style())
+	}
+	for i, candidate := range p.candidates() {
+		if i == completionsLimit {
+			p.drawLine(2+i, "…", p.stCompletion.Style())
+			break
+		}
+		style := p.stCompletion.Style()
+		if i == p.completion {
+			style = style.Background(theme.ColorSelectedFocusedBackground)
+		}
+		p.drawLine(2+i, candidate, style)
+	}
+}
+
+func (p *prompt) drawLine(y int, str string, style tcell.Style) {
+	x := 0
+	for _, ch := range str {
+		p.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+}
+
+func (p *prompt) Resize() {
+}
+
+// complete cycles through completions of the line. Single completion is applied right away,
+// so the next word could be completed
+func (p *prompt) complete(delta int) {
+	if p.completion != -1 {
+		p.completion = (p.completion + delta + len(p.completions)) % len(p.completions)
+		p.input.SetText(Apply(p.base, p.completions[p.completion]))
+		return
+	}
+	p.base = p.input.Text()
+	p.completions = p.completer.Complete(p.base)
+	if len(p.completions) > completionsLimit {
+		p.completions = p.completions[:completionsLimit]
+	}
+	switch {
+	case len(p.completions) == 0:
+		return
+	case len(p.completions) == 1:
+		p.input.SetText(Apply(p.base, p.completions[0]))
+		return
+	case delta > 0:
+		p.completion = 0
+	default:
+		p.completion = len(p.completions) - 1
+	}
+	p.input.SetText(Apply(p.base, p.completions[p.completion]))
+}
+
+func (p *prompt) browseHistory(delta int) {
+	index := p.historyIndex + delta
+	if index < -1 || index >= len(p.history) {
+		return
+	}
+	if p.historyIndex == -1 {
+		p.typed = p.input.Text()
+	}
+	p.historyIndex = index
+	if index == -1 {
+		p.input.SetText(p.typed)
+	} else {
+		p.input.SetText(p.history[index])
+	}
+	p.completion = -1
+}
+
+func (p *prompt) submit() {
+	cmd, err := Parse(p.input.Text())
+	if err == nil {
+		err = p.run(cmd)
+	}
+	p.err = err
+	if err != nil {
+		return
+	}
+	p.history = addHistory(p.history, cmd.String())
+	if err := p.container.StateStore().Save(historyKey, p.history); err != nil {
+		p.container.Status().Error(err)
+	}
+}
+
+func (p *prompt) HandleEvent(ev tcell.Event) bool {
+	e, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	switch {
+	case popup.ActApply.Matches(e):
+		p.submit()
+	case popup.ActNext.Matches(e):
+		p.complete(1)
+	case popup.ActPrev.Matches(e):
+		p.complete(-1)
+	case e.Key() == tcell.KeyUp:
+		p.browseHistory(1)
+	case e.Key() == tcell.KeyDown:
+		p.browseHistory(-1)
+	case p.input.HandleEvent(ev):
+		p.completion = -1
+		p.err = nil
+	default:
+		return false
+	}
+	return true
+}
+
+func (p *prompt) SetView(view views.View) {
+	p.view = view
+	w, _ := view.Size()
+	p.input.SetView(views.NewViewPort(view, 0, 0, w, 1))
+}
+
+func (p *prompt) Size() (int, int) {
+	return p.MaxSize()
+}
+
+// MaxSize has room for all completions, so the popup doesn't jump while typing
+func (p *prompt) MaxSize() (int, int) {
+	w, _ := p.input.MaxSize()
+	if width := runewidth.StringWidth(promptHint()); width > w {
+		w = width
+	}
+	return w, 3 + completionsLimit
+}
+
+// Show shows command line in a popup
+func Show(workspace commander.Workspace, completer *Completer, run RunFunc) {
+	workspace.ShowPopup("Command", newPrompt(completer, workspace, run))
+}
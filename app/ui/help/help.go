@@ -1,59 +1,285 @@
 package help
 
 import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
 	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+	"strings"
 )
 
+const (
+	intro            = "kube-commander - browse your Kubernetes cluster in a casual way!"
+	searchInputWidth = 30
+	// Lines above the help lines: intro, search and an empty line
+	headerLines = 3
+	wheelLines  = 3
+)
+
+type line struct {
+	key   string
+	text  string
+	title bool
+}
+
+type section struct {
+	title string
+	lines []line
+}
+
+// Help which isn't about actions
+var notes = []section{
+	{
+		title: "Filter syntax",
+		lines: []line{
+			{key: "word", text: "Cells containing the word, case-insensitive unless it has upper case letters"},
+			{key: "!term", text: "Rows not matching the term"},
+			{key: "/regex/", text: "Cells matching regular expression"},
+			{key: "status:Running", text: "Match the column only"},
+			{key: "restarts>3", text: "Compare numbers, ages and quantities with >, <, >=, <=, ="},
+			{key: "Tab", text: "Switch between query and fuzzy matching"},
+		},
+	},
+}
+
+var mouseNotes = section{
+	title: "Mouse",
+	lines: []line{
+		{key: "Click", text: "Select row or menu item"},
+		{key: "Double click", text: "Same as Enter"},
+		{key: "Wheel", text: "Scroll"},
+		{key: "Click on header", text: "Sort by the column"},
+		{key: "Click outside", text: "Close popup"},
+	},
+}
+
+// widget shows keys of actions grouped by scope. Lines could be searched by keys and descriptions
 type widget struct {
-	*views.Text
+	views.WidgetWatchers
 	*focus.Focusable
+
+	view      views.View
+	scopes    []actions.Scope
+	search    *input.Input
+	searching bool
+	top       int
+
+	stIntro commander.StyleComponent
+	stTitle commander.StyleComponent
+	stKey   commander.StyleComponent
+	stText  commander.StyleComponent
 }
 
-func (w widget) MaxSize() (int, int) {
-	return w.Text.Size()
+// NewHelpWidget creates help on actions of the scopes
+func NewHelpWidget(scopes []actions.Scope) *widget {
+	return &widget{
+		Focusable: focus.NewFocusable(),
+		scopes:    scopes,
+		search:    input.NewInput(listTable.ActFilter.Hint("search")+" ", "", searchInputWidth),
+
+		stIntro: theme.NewComponent("intro", theme.Default.Bold(true)),
+		stTitle: theme.NewComponent("title", theme.Default.Underline(true)),
+		stKey:   theme.NewComponent("key", theme.Default.Foreground(tcell.ColorYellow)),
+		stText:  theme.NewComponent("text", theme.Default),
+	}
 }
 
-func (w widget) Size() (int, int) {
-	width, _ := w.Text.Size()
-	return width, 1
+func (w *widget) GetComponents() []commander.StyleComponent {
+	return append(w.search.GetComponents(), w.stIntro, w.stTitle, w.stKey, w.stText)
 }
 
-var text = `kube-commander - browse your Kubernetes cluster in a casual way!
+func (w *widget) sections() []section {
+	var sections []section
+	for _, scope := range w.scopes {
+		s := section{title: string(scope)}
+		for _, a := range actions.Bound(scope) {
+			s.lines = append(s.lines, line{key: a.KeyNames(), text: a.Description})
+		}
+		sections = append(sections, s)
+	}
+	sections = append(sections, notes...)
+	if mouse.Enabled {
+		sections = append(sections, mouseNotes)
+	}
+	return sections
+}
 
-Global:
- D: Describe selected resource 				?: Shows help dialog
- E: Edit selected resource 					Q: Quit
- C: Copy resource name to the clipboard 	Ctrl+N or F2: Switch namespace
- Del: Delete resource (with confirmation)
+// lines returns lines matching the search. Matching section title shows the whole section
+func (w *widget) lines() []line {
+	query := strings.ToLower(w.search.Text())
+	var lines []line
+	for _, s := range w.sections() {
+		all := strings.Contains(strings.ToLower(s.title), query)
+		var matched []line
+		for _, l := range s.lines {
+			if all || strings.Contains(strings.ToLower(l.key+" "+l.text), query) {
+				matched = append(matched, l)
+			}
+		}
+		if len(matched) > 0 {
+			if len(lines) > 0 {
+				lines = append(lines, line{})
+			}
+			lines = append(lines, line{text: s.title, title: true})
+			lines = append(lines, matched...)
+		}
+	}
+	return lines
+}
 
-Navigation:
- ↑↓→←: List navigation            /: Filter resources
- Enter: Select menu item          Esc, Backspace: Go back
+func keyWidth(lines []line) int {
+	width := 0
+	for _, l := range lines {
+		if w := runewidth.StringWidth(l.key); !l.title && w > width {
+			width = w
+		}
+	}
+	return width
+}
 
-Resource types navigation:
- Ctrl+P: Pods
- Ctrl+D: Deployments              Ctrl+I: Ingresses
+func (w *widget) Draw() {
+	w.view.Fill(' ', theme.Default)
+	w.drawText(0, 0, intro, w.stIntro.Style())
+	w.search.Draw()
+	_, h := w.view.Size()
+	lines := w.lines()
+	width := keyWidth(lines)
+	for y := headerLines; y < h; y++ {
+		i := w.top + y - headerLines
+		if i >= len(lines) {
+			break
+		}
+		l := lines[i]
+		if l.title {
+			w.drawText(0, y, l.text, w.stTitle.Style())
+			continue
+		}
+		w.drawText(1, y, l.key, w.stKey.Style())
+		w.drawText(width+3, y, l.text, w.stText.Style())
+	}
+}
 
-Pods:
- L: Show logs                     Shift+L: Show previous logs
- F: Forward port
- S: Shell into selected pod
-`
+func (w *widget) drawText(x int, y int, str string, style tcell.Style) {
+	for _, ch := range str {
+		w.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+}
 
-func NewHelpWidget() *widget {
-	widget := widget{
-		Text:      views.NewText(),
-		Focusable: focus.NewFocusable(),
+func (w *widget) Resize() {
+}
+
+func (w *widget) scroll(delta int) {
+	_, h := w.view.Size()
+	w.top += delta
+	if max := len(w.lines()) - (h - headerLines); w.top > max {
+		w.top = max
+	}
+	if w.top < 0 {
+		w.top = 0
+	}
+}
+
+func (w *widget) setSearching(searching bool) {
+	w.searching = searching
+	if searching {
+		w.search.OnFocus()
+	} else {
+		w.search.OnBlur()
+	}
+}
+
+func (w *widget) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventMouse); ok {
+		return w.handleMouse(e)
+	}
+	e, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	_, h := w.view.Size()
+	page := h - headerLines
+	switch {
+	case w.searching && e.Key() == tcell.KeyEnter:
+		w.setSearching(false)
+	case (w.searching || w.search.Text() != "") && listTable.ActClearFilter.Matches(e):
+		w.search.SetText("")
+		w.setSearching(false)
+		w.top = 0
+	case w.searching && w.search.HandleEvent(ev):
+		w.top = 0
+	case !w.searching && listTable.ActFilter.Matches(e):
+		w.setSearching(true)
+	case listTable.ActDown.Matches(e):
+		w.scroll(1)
+	case listTable.ActUp.Matches(e):
+		w.scroll(-1)
+	case listTable.ActPageDown.Matches(e):
+		w.scroll(page)
+	case listTable.ActPageUp.Matches(e):
+		w.scroll(-page)
+	case listTable.ActHome.Matches(e):
+		w.top = 0
+	case listTable.ActEnd.Matches(e):
+		w.scroll(len(w.lines()))
+	default:
+		return false
+	}
+	return true
+}
+
+func (w *widget) handleMouse(ev *tcell.EventMouse) bool {
+	local, ok := mouse.Local(ev, w.view)
+	if !ok {
+		return false
+	}
+	switch {
+	case local.Buttons()&tcell.WheelDown != 0:
+		w.scroll(wheelLines)
+	case local.Buttons()&tcell.WheelUp != 0:
+		w.scroll(-wheelLines)
+	}
+	return true
+}
+
+func (w *widget) SetView(view views.View) {
+	w.view = view
+	width, _ := view.Size()
+	w.search.SetView(views.NewViewPort(view, 0, 1, width, 1))
+}
+
+func (w *widget) Size() (int, int) {
+	width, _ := w.MaxSize()
+	return width, 1
+}
+
+// MaxSize fits all lines, so the popup doesn't change its size while searching
+func (w *widget) MaxSize() (int, int) {
+	var lines []line
+	for _, s := range w.sections() {
+		lines = append(lines, line{}, line{text: s.title, title: true})
+		lines = append(lines, s.lines...)
+	}
+	width := runewidth.StringWidth(intro)
+	if searchWidth, _ := w.search.Size(); searchWidth > width {
+		width = searchWidth
+	}
+	keys := keyWidth(lines)
+	for _, l := range lines {
+		if lw := keys + 3 + runewidth.StringWidth(l.text); lw > width {
+			width = lw
+		}
 	}
-	widget.Text.SetText(text)
-	widget.Text.SetStyle(theme.Default)
-	return &widget
+	return width, headerLines + len(lines) - 1
 }
 
-func ShowHelpPopup(workspace commander.Workspace) {
-	help := NewHelpWidget()
-	workspace.ShowPopup("Help", help)
+// ShowHelpPopup shows help on actions of the scopes
+func ShowHelpPopup(workspace commander.Workspace, scopes []actions.Scope) {
+	workspace.ShowPopup("Help", NewHelpWidget(scopes))
 }
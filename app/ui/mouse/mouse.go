@@ -0,0 +1,78 @@
+package mouse
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"time"
+)
+
+const (
+	// Maximum interval between clicks of a double click
+	DoubleClickInterval = 400 * time.Millisecond
+
+	buttons = tcell.Button1 | tcell.Button2 | tcell.Button3
+	wheel   = tcell.WheelUp | tcell.WheelDown | tcell.WheelLeft | tcell.WheelRight
+)
+
+// Enabled turns mouse capture on. Users who rely on terminal selection could turn it off
+var Enabled = true
+
+type physical interface {
+	GetPhysical() (int, int, int, int)
+}
+
+// Local translates the event into coordinates of the view and reports whether it is within the view.
+// Widgets receive mouse events in coordinates of their view's parent, so every widget translates them on its own
+func Local(ev *tcell.EventMouse, view views.View) (*tcell.EventMouse, bool) {
+	if view == nil {
+		return ev, false
+	}
+	x, y := ev.Position()
+	if p, ok := view.(physical); ok {
+		px, py, _, _ := p.GetPhysical()
+		x, y = x-px, y-py
+	}
+	w, h := view.Size()
+	local := tcell.NewEventMouse(x, y, ev.Buttons(), ev.Modifiers())
+	return local, x >= 0 && y >= 0 && x < w && y < h
+}
+
+// Clicks turns the stream of mouse events into clicks. Terminals report every mouse motion while a button
+// is held, so only the event which presses the button counts
+type Clicks struct {
+	pressed tcell.ButtonMask
+}
+
+// Filter returns event to pass on: button press or wheel motion. Motion and release events are dropped
+func (c *Clicks) Filter(ev *tcell.EventMouse) (*tcell.EventMouse, bool) {
+	held := ev.Buttons() & buttons
+	pressed := held &^ c.pressed
+	c.pressed = held
+	wheeled := ev.Buttons() & wheel
+	if pressed == 0 && wheeled == 0 {
+		return nil, false
+	}
+	x, y := ev.Position()
+	return tcell.NewEventMouse(x, y, pressed|wheeled, ev.Modifiers()), true
+}
+
+// IsClick reports whether a button is pressed
+func IsClick(ev *tcell.EventMouse) bool {
+	return ev.Buttons()&buttons != 0
+}
+
+// DoubleClicks detects second click on the same item within DoubleClickInterval
+type DoubleClicks struct {
+	index int
+	at    time.Time
+}
+
+// Click records click on the item and reports whether it completes a double click
+func (d *DoubleClicks) Click(index int) bool {
+	if index == d.index && time.Since(d.at) < DoubleClickInterval {
+		d.at = time.Time{}
+		return true
+	}
+	d.index, d.at = index, time.Now()
+	return false
+}
@@ -0,0 +1,76 @@
+package palette
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+)
+
+// Lines taken by the filter and headers
+const headerLines = 2
+
+type RunFunc func(a *actions.Action)
+
+// palette lists actions with their keys and runs the picked one. Typing fuzzy-searches actions
+type palette struct {
+	*listTable.ListTable
+
+	run RunFunc
+	// Size of the full list, so the popup doesn't shrink while searching
+	width  int
+	height int
+}
+
+func newPalette(available []*actions.Action, run RunFunc) *palette {
+	var rows []commander.Row
+	for _, a := range available {
+		rows = append(rows, commander.NewSimpleRow(a.Id, []string{a.Description, a.KeyNames(), string(a.Scope)}, true))
+	}
+	p := &palette{
+		ListTable: listTable.NewStaticListTable([]string{"Action", "Keys", "Scope"}, rows, listTable.WithHeaders|listTable.NoHorizontalScroll),
+		run:       run,
+	}
+	p.width, _ = p.ListTable.MaxSize()
+	p.height = headerLines + len(rows)
+	p.EditFilter(listTable.Filter{Fuzzy: true})
+	p.BindOnAction(func(row commander.Row, a *actions.Action) bool {
+		if row != nil && a == popup.ActApply {
+			p.run(actions.Get(row.Id()))
+			return true
+		}
+		return false
+	}, actions.Popup)
+	p.SetDoubleClickAction(popup.ActApply)
+	return p
+}
+
+func (p *palette) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case popup.ActApply.Matches(e):
+			if row := p.SelectedRow(); row != nil {
+				p.run(actions.Get(row.Id()))
+			}
+			return true
+		case listTable.ActClearFilter.Matches(e):
+			// Leaves the key to close the palette right away
+			return false
+		}
+	}
+	return p.ListTable.HandleEvent(ev)
+}
+
+func (p *palette) MaxSize() (int, int) {
+	return p.width, p.height
+}
+
+// Show shows actions in a popup. Picked action is passed to the run function
+func Show(workspace commander.Workspace, available []*actions.Action, run RunFunc) {
+	if len(available) == 0 {
+		workspace.Status().Info("No actions available")
+		return
+	}
+	workspace.ShowPopup("Actions", newPalette(available, run))
+}
@@ -0,0 +1,210 @@
+package resourceMenu
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+)
+
+const (
+	favoritePrefix   = "favorite/"
+	titleInputWidth  = 30
+	favoritesTitle   = "★ Favorites"
+	favoritesSection = "__favorites__"
+)
+
+var (
+	actPin      = actions.Register("menu.pin", "Pin or unpin resource type to favorites", actions.Menu, "f")
+	actRename   = actions.Register("menu.rename", "Rename favorite", actions.Menu, "r")
+	actMoveUp   = actions.Register("menu.moveUp", "Move favorite up", actions.Menu, "Shift+Up")
+	actMoveDown = actions.Register("menu.moveDown", "Move favorite down", actions.Menu, "Shift+Down")
+)
+
+type favoritesHeader struct{}
+
+func (f favoritesHeader) Id() string {
+	return favoritesSection
+}
+
+func (f favoritesHeader) Cells() []string {
+	return []string{favoritesTitle}
+}
+
+func (f favoritesHeader) OnSelect() bool {
+	panic("implement me")
+}
+
+func (f favoritesHeader) Enabled() bool {
+	return false
+}
+
+// favoriteItem is a pinned resource type. It opens the widget of the menu item of the same kind
+type favoriteItem struct {
+	*resourceItem
+	title      string
+	decoration string
+}
+
+func (f favoriteItem) Id() string {
+	return favoritePrefix + f.gk.String()
+}
+
+func (f favoriteItem) Cells() []string {
+	return []string{f.decoration + f.title}
+}
+
+func (r *ResourceMenu) favorites() []commander.Favorite {
+	return r.workspace.SettingsStore().Settings().Favorites
+}
+
+// favoriteIndex returns index of the resource type in favorites, -1 if it isn't pinned
+func (r *ResourceMenu) favoriteIndex(gk schema.GroupKind) int {
+	for i, favorite := range r.favorites() {
+		if schema.ParseGroupKind(favorite.Kind) == gk {
+			return i
+		}
+	}
+	return -1
+}
+
+// item returns menu item of the resource type, including extra ones
+func (r *ResourceMenu) item(gk schema.GroupKind) *resourceItem {
+	items := r.items
+	for _, g := range r.groups {
+		items = append(items[:len(items):len(items)], g.items...)
+	}
+	for _, item := range items {
+		if item.gk == gk {
+			return item
+		}
+	}
+	return nil
+}
+
+func (r *ResourceMenu) buildFavorites() []*favoriteItem {
+	var items []*favoriteItem
+	favorites := r.favorites()
+	for i, favorite := range favorites {
+		gk := schema.ParseGroupKind(favorite.Kind)
+		item := r.item(gk)
+		if item == nil {
+			// Resource type isn't discovered yet, or doesn't exist in the cluster
+			item = r.buildItem(gk, nil)
+		}
+		title := favorite.Title
+		if title == "" {
+			title = item.title
+		}
+		decoration := " ├"
+		if i == len(favorites)-1 {
+			decoration = " └"
+		}
+		items = append(items, &favoriteItem{
+			resourceItem: item,
+			title:        title,
+			decoration:   decoration,
+		})
+	}
+	return items
+}
+
+// favoriteRows returns number of rows taken by favorites section
+func (r *ResourceMenu) favoriteRows() int {
+	if len(r.favoriteItems) == 0 {
+		return 0
+	}
+	return len(r.favoriteItems) + 1
+}
+
+// favoriteOps replaces favorites section on top of the menu. Section is hidden when nothing is pinned
+func (r *ResourceMenu) favoriteOps() []commander.Operation {
+	var ops []commander.Operation
+	if r.favoriteRows() > 0 {
+		ops = append(ops, &commander.OpDeleted{RowId: favoritesSection})
+	}
+	for _, item := range r.favoriteItems {
+		ops = append(ops, &commander.OpDeleted{RowId: item.Id()})
+	}
+	r.favoriteItems = r.buildFavorites()
+	if len(r.favoriteItems) == 0 {
+		return ops
+	}
+	index := 0
+	ops = append(ops, &commander.OpAdded{Row: favoritesHeader{}, Index: &index})
+	for i, item := range r.favoriteItems {
+		index := i + 1
+		ops = append(ops, &commander.OpAdded{Row: item, Index: &index})
+	}
+	return ops
+}
+
+// setFavorites saves favorites to the config file and shows them in the menu
+func (r *ResourceMenu) setFavorites(favorites []commander.Favorite) {
+	store := r.workspace.SettingsStore()
+	store.Settings().Favorites = favorites
+	if err := store.Save(); err != nil {
+		r.workspace.Status().Error(err)
+	}
+	r.Apply(r.favoriteOps())
+}
+
+func (r *ResourceMenu) togglePin(gk schema.GroupKind) {
+	favorites := r.favorites()
+	if i := r.favoriteIndex(gk); i != -1 {
+		r.setFavorites(append(favorites[:i:i], favorites[i+1:]...))
+		return
+	}
+	r.setFavorites(append(favorites[:len(favorites):len(favorites)], commander.Favorite{Kind: gk.String()}))
+}
+
+func (r *ResourceMenu) moveFavorite(gk schema.GroupKind, delta int) {
+	i := r.favoriteIndex(gk)
+	j := i + delta
+	if i == -1 || j < 0 || j >= len(r.favorites()) {
+		return
+	}
+	favorites := append([]commander.Favorite{}, r.favorites()...)
+	favorites[i], favorites[j] = favorites[j], favorites[i]
+	r.setFavorites(favorites)
+}
+
+// renameFavorite asks for a new title of the favorite. Empty title restores the default one
+func (r *ResourceMenu) renameFavorite(item *favoriteItem) {
+	prompt := newTitlePrompt(item.title, func(title string) {
+		r.workspace.FocusManager().Blur()
+		i := r.favoriteIndex(item.gk)
+		if i == -1 {
+			return
+		}
+		favorites := append([]commander.Favorite{}, r.favorites()...)
+		favorites[i].Title = strings.TrimSpace(title)
+		r.setFavorites(favorites)
+	})
+	r.workspace.ShowPopup("Rename", prompt)
+}
+
+type titlePrompt struct {
+	*input.Input
+	apply func(title string)
+}
+
+func newTitlePrompt(title string, apply func(title string)) *titlePrompt {
+	p := &titlePrompt{
+		Input: input.NewInput("Title: ", title, titleInputWidth),
+		apply: apply,
+	}
+	p.OnFocus()
+	return p
+}
+
+func (p *titlePrompt) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventKey); ok && popup.ActApply.Matches(e) {
+		p.apply(p.Text())
+		return true
+	}
+	return p.Input.HandleEvent(ev)
+}
@@ -0,0 +1,180 @@
+package resourceMenu
+
+import (
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"sort"
+	"strings"
+)
+
+const (
+	groupPrefix    = "group/"
+	coreGroupTitle = "core"
+)
+
+// groupNode is a collapsible API group of extra resource types. Rows are copies of the node,
+// so the table notices when the node is expanded or collapsed
+type groupNode struct {
+	group    string
+	items    []*resourceItem
+	expanded bool
+	// Group was expanded by the filter, so it is collapsed back once the filter is cleared
+	autoExpanded bool
+}
+
+func (g groupNode) Id() string {
+	return groupPrefix + g.group
+}
+
+func (g groupNode) Cells() []string {
+	arrow := "▸ "
+	if g.expanded {
+		arrow = "▾ "
+	}
+	name := g.group
+	if name == "" {
+		name = coreGroupTitle
+	}
+	return []string{fmt.Sprintf("%s%s (%d)", arrow, name, len(g.items))}
+}
+
+func (g groupNode) OnSelect() bool {
+	panic("implement me")
+}
+
+func (g groupNode) Enabled() bool {
+	return true
+}
+
+// Keywords makes the group match filter by titles of its resource types, so it is shown above them
+func (g groupNode) Keywords() []string {
+	var keywords []string
+	for _, item := range g.items {
+		keywords = append(keywords, item.title)
+	}
+	return keywords
+}
+
+// rows returns number of rows the group takes
+func (g *groupNode) rows() int {
+	if g.expanded {
+		return 1 + len(g.items)
+	}
+	return 1
+}
+
+// groupItems groups extra resource types by API group. Groups and their items are sorted, so the menu is stable
+func groupItems(items []*resourceItem) []*groupNode {
+	var groups []*groupNode
+	byGroup := make(map[string]*groupNode)
+	for _, item := range items {
+		g, ok := byGroup[item.gk.Group]
+		if !ok {
+			g = &groupNode{group: item.gk.Group}
+			byGroup[item.gk.Group] = g
+			groups = append(groups, g)
+		}
+		g.items = append(g.items, item)
+	}
+	// Core group has empty name, so it goes first
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].group < groups[j].group
+	})
+	for _, g := range groups {
+		sort.Slice(g.items, func(i, j int) bool {
+			a, b := g.items[i], g.items[j]
+			if a.title != b.title {
+				return strings.ToLower(a.title) < strings.ToLower(b.title)
+			}
+			return a.gk.Kind < b.gk.Kind
+		})
+		for i, item := range g.items {
+			if i == len(g.items)-1 {
+				item.decoration = "  └"
+			} else {
+				item.decoration = "  ├"
+			}
+		}
+	}
+	return groups
+}
+
+// extraRows returns rows of extra resource types: groups and items of expanded groups
+func (r *ResourceMenu) extraRows() []commander.Row {
+	var rows []commander.Row
+	for _, g := range r.groups {
+		rows = append(rows, *g)
+		if g.expanded {
+			for _, item := range g.items {
+				rows = append(rows, item)
+			}
+		}
+	}
+	return rows
+}
+
+// extraIndex returns index of the first row of extra resource types, which go after the namespaced ones
+func (r *ResourceMenu) extraIndex() int {
+	return r.favoriteRows() + len(r.items) + 1
+}
+
+func (r *ResourceMenu) group(id string) *groupNode {
+	for _, g := range r.groups {
+		if g.Id() == id {
+			return g
+		}
+	}
+	return nil
+}
+
+// toggleGroup expands or collapses the group
+func (r *ResourceMenu) toggleGroup(g *groupNode) {
+	index := r.extraIndex()
+	for _, other := range r.groups {
+		if other == g {
+			break
+		}
+		index += other.rows()
+	}
+	g.expanded = !g.expanded
+	g.autoExpanded = false
+	ops := []commander.Operation{&commander.OpModified{Row: *g}}
+	for i, item := range g.items {
+		if g.expanded {
+			at := index + 1 + i
+			ops = append(ops, &commander.OpAdded{Row: item, Index: &at})
+		} else {
+			ops = append(ops, &commander.OpDeleted{RowId: item.Id()})
+		}
+	}
+	r.Apply(ops)
+}
+
+// expandMatching expands groups with resource types matching the filter. Once the filter is cleared,
+// they are collapsed back
+func (r *ResourceMenu) expandMatching(filter listTable.Filter) {
+	if !r.showExtra {
+		return
+	}
+	for _, g := range r.groups {
+		switch {
+		case filter.Text == "":
+			if g.autoExpanded {
+				r.toggleGroup(g)
+			}
+		case !g.expanded && r.matchesAny(g.items):
+			r.toggleGroup(g)
+			g.autoExpanded = true
+		}
+	}
+}
+
+func (r *ResourceMenu) matchesAny(items []*resourceItem) bool {
+	for _, item := range items {
+		if r.Matches(item) {
+			return true
+		}
+	}
+	return false
+}
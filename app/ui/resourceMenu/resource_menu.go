@@ -1,21 +1,22 @@
 package resourceMenu
 
 import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/client"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/resources/pod"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
 	"github.com/AnatolyRugalev/kube-commander/commander"
-	"github.com/gdamore/tcell"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"strings"
 )
 
 type resourceItem struct {
-	title      string
-	gk         schema.GroupKind
-	resource   *commander.Resource
-	widget     commander.Widget
-	decoration string
+	title       string
+	gk          schema.GroupKind
+	resource    *commander.Resource
+	widget      commander.Widget
+	constructor func() commander.Widget
+	decoration  string
 }
 
 func (r resourceItem) Id() string {
@@ -27,7 +28,15 @@ func (r resourceItem) Cells() []string {
 }
 
 func (r resourceItem) Enabled() bool {
-	return r.widget != nil
+	return r.resource != nil
+}
+
+// Widget constructs resource widget on first access
+func (r *resourceItem) Widget() commander.Widget {
+	if r.widget == nil && r.constructor != nil {
+		r.widget = r.constructor()
+	}
+	return r.widget
 }
 
 func (r resourceItem) OnSelect() bool {
@@ -116,10 +125,10 @@ type SelectFunc func(itemId string, widget commander.Widget) bool
 type ResourceMenu struct {
 	*listTable.ListTable
 
-	clusterItems         int
-	extraClusterItems    []*resourceItem
-	extraNamespacedItems []*resourceItem
-	showExtra            bool
+	items         []*resourceItem
+	favoriteItems []*favoriteItem
+	groups        []*groupNode
+	showExtra     bool
 
 	onSelect        SelectFunc
 	selectNamespace func()
@@ -140,7 +149,9 @@ func NewResourcesMenu(workspace commander.Workspace, onSelect SelectFunc, select
 		rowProvider:     prov,
 		workspace:       workspace,
 	}
-	lt.BindOnKeyPress(r.OnKeyPress)
+	lt.BindOnAction(r.OnAction, actions.Menu)
+	lt.SetDoubleClickAction(actOpen)
+	lt.BindOnFilter(r.expandMatching)
 	return r, nil
 }
 
@@ -155,8 +166,9 @@ func (r *ResourceMenu) provideItems() {
 
 	cluster, namespaced := r.splitResources(client.CoreResources())
 	clusterItems, _ := r.buildResourceItems(cluster, clusterGKs)
-	r.clusterItems = len(clusterItems)
 	namespacedItems, _ := r.buildResourceItems(namespaced, namespacedGKs)
+	r.items = append(clusterItems, namespacedItems...)
+	ops = append(ops, r.favoriteOps()...)
 	for _, item := range clusterItems {
 		item.decoration = " "
 		ops = append(ops, &commander.OpAdded{Row: item})
@@ -179,8 +191,11 @@ func (r *ResourceMenu) provideItems() {
 	}
 	ops = []commander.Operation{}
 	cluster, namespaced = r.splitResources(serverResources)
-	clusterItems, r.extraClusterItems = r.buildResourceItems(cluster, clusterGKs)
-	namespacedItems, r.extraNamespacedItems = r.buildResourceItems(namespaced, namespacedGKs)
+	clusterItems, extraClusterItems := r.buildResourceItems(cluster, clusterGKs)
+	namespacedItems, extraNamespacedItems := r.buildResourceItems(namespaced, namespacedGKs)
+	r.groups = groupItems(append(extraClusterItems, extraNamespacedItems...))
+	r.items = append(clusterItems, namespacedItems...)
+	ops = append(ops, r.favoriteOps()...)
 	for _, item := range clusterItems {
 		item.decoration = " "
 		ops = append(ops, &commander.OpModified{Row: item})
@@ -193,6 +208,11 @@ func (r *ResourceMenu) provideItems() {
 		}
 		ops = append(ops, &commander.OpModified{Row: item})
 	}
+	if r.showExtra {
+		for _, row := range r.extraRows() {
+			ops = append(ops, &commander.OpAdded{Row: row})
+		}
+	}
 	ops = append(ops, &commander.OpInitFinished{})
 	r.rowProvider <- ops
 }
@@ -223,58 +243,132 @@ func plural(s string) string {
 	}
 }
 
-func (r *ResourceMenu) OnKeyPress(row commander.Row, event *tcell.EventKey) bool {
-	if event.Key() == tcell.KeyEnter {
+var (
+	actOpen  = actions.Register("menu.open", "Open selected resource type", actions.Menu, "Enter")
+	actExtra = actions.Register("menu.extra", "Show or hide extra resource types", actions.Menu, "F3")
+)
+
+func (r *ResourceMenu) ActionScopes() []actions.Scope {
+	return append([]actions.Scope{actions.Menu}, r.ListTable.ActionScopes()...)
+}
+
+func (r *ResourceMenu) OnAction(row commander.Row, a *actions.Action) bool {
+	if a == actOpen {
 		switch i := row.(type) {
 		case *resourceItem:
-			r.onSelect(row.Id(), i.widget)
+			r.onSelect(row.Id(), i.Widget())
+		case *favoriteItem:
+			r.onSelect(row.Id(), i.Widget())
+		case groupNode:
+			r.toggleGroup(r.group(i.Id()))
 		case *namespaceSelector:
 			r.selectNamespace()
 		}
 		return true
 	}
-	if event.Key() == tcell.KeyF3 {
-		go r.toggleExtra()
+	if a == actExtra {
+		r.toggleExtra()
+		return true
+	}
+	switch i := row.(type) {
+	case *resourceItem:
+		if a == actPin {
+			r.togglePin(i.gk)
+			return true
+		}
+	case *favoriteItem:
+		switch a {
+		case actPin:
+			r.togglePin(i.gk)
+		case actRename:
+			r.renameFavorite(i)
+		case actMoveUp:
+			r.moveFavorite(i.gk, -1)
+		case actMoveDown:
+			r.moveFavorite(i.gk, 1)
+		default:
+			return false
+		}
 		return true
 	}
 	return false
 }
 
+// ActionEnabled hides favorites actions when they don't apply to the selected item
+func (r *ResourceMenu) ActionEnabled(a *actions.Action) bool {
+	switch row := r.SelectedRow(); a {
+	case actPin:
+		switch row.(type) {
+		case *resourceItem, *favoriteItem:
+			return true
+		}
+		return false
+	case actRename, actMoveUp, actMoveDown:
+		_, ok := row.(*favoriteItem)
+		return ok
+	}
+	return true
+}
+
+// toggleExtra shows or hides resource types which aren't in the menu by default, grouped by API group
 func (r *ResourceMenu) toggleExtra() {
 	var ops []commander.Operation
-	if r.showExtra {
-		for _, item := range r.extraClusterItems {
-			ops = append(ops, &commander.OpDeleted{
-				RowId: item.Id(),
-			})
-		}
-		for _, item := range r.extraNamespacedItems {
-			ops = append(ops, &commander.OpDeleted{
-				RowId: item.Id(),
-			})
-		}
-	} else {
-		for i, item := range r.extraClusterItems {
-			index := r.clusterItems + i
-			ops = append(ops, &commander.OpAdded{
-				Row:   item,
-				Index: &index,
-			})
-		}
-		for _, item := range r.extraNamespacedItems {
-			ops = append(ops, &commander.OpAdded{
-				Row:      item,
-				SortById: false,
-			})
+	for i, row := range r.extraRows() {
+		if r.showExtra {
+			ops = append(ops, &commander.OpDeleted{RowId: row.Id()})
+		} else {
+			index := r.extraIndex() + i
+			ops = append(ops, &commander.OpAdded{Row: row, Index: &index})
 		}
 	}
-	r.rowProvider <- ops
 	r.showExtra = !r.showExtra
+	r.Apply(ops)
 }
+
 func (r *ResourceMenu) SelectItem(id string) {
 	r.ListTable.SelectId(id)
 }
 
+// OpenItem opens the item by its id, including favorites
+func (r *ResourceMenu) OpenItem(id string) bool {
+	for _, item := range r.favoriteItems {
+		if item.Id() == id && item.Widget() != nil {
+			r.SelectItem(id)
+			return r.onSelect(id, item.Widget())
+		}
+	}
+	if strings.HasPrefix(id, favoritePrefix) {
+		return false
+	}
+	return r.Open(schema.ParseGroupKind(id))
+}
+
+// Open opens widget of the resource type. Extra resource types and the group are expanded if the type is one of them
+func (r *ResourceMenu) Open(gk schema.GroupKind) bool {
+	for _, item := range r.items {
+		if item.gk == gk && item.Widget() != nil {
+			r.SelectItem(item.Id())
+			return r.onSelect(item.Id(), item.Widget())
+		}
+	}
+	for _, g := range r.groups {
+		for _, item := range g.items {
+			if item.gk != gk {
+				continue
+			}
+			if !r.showExtra {
+				r.toggleExtra()
+			}
+			if !g.expanded {
+				r.toggleGroup(g)
+			}
+			r.SelectItem(item.Id())
+			return r.onSelect(item.Id(), item.Widget())
+		}
+	}
+	return false
+}
+
 func (r *ResourceMenu) buildResourceItems(resources commander.ResourceMap, gks []schema.GroupKind) ([]*resourceItem, []*resourceItem) {
 	var items []*resourceItem
 	var leftovers []*resourceItem
@@ -310,7 +404,9 @@ func (r *ResourceMenu) buildItem(gk schema.GroupKind, res *commander.Resource) *
 			constructor = StandardWidget
 		}
 		item.resource = res
-		item.widget = constructor(r.workspace, res, listTable.Wide|listTable.WithHeaders|listTable.WithFilter)
+		item.constructor = func() commander.Widget {
+			return constructor(r.workspace, res, listTable.Wide|listTable.WithHeaders|listTable.WithFilter|listTable.WithSort|listTable.WithMarks)
+		}
 	}
 	return item
 }
@@ -1,9 +1,10 @@
 package namespace
 
 import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
 	"github.com/AnatolyRugalev/kube-commander/commander"
-	"github.com/gdamore/tcell"
 )
 
 type NamespaceFunc func(namespace string)
@@ -23,15 +24,16 @@ func PickNamespace(workspace commander.Workspace, resource *commander.Resource,
 
 func NewNamespacePicker(container commander.ResourceContainer, resource *commander.Resource, f NamespaceFunc) (*listTable.ResourceListTable, error) {
 	rlt := listTable.NewResourceListTable(container, resource, listTable.NameOnly|listTable.NoActions|listTable.NoWatch)
-	rlt.BindOnKeyPress(func(row commander.Row, event *tcell.EventKey) bool {
-		if event.Key() == tcell.KeyEnter {
+	rlt.BindOnAction(func(row commander.Row, a *actions.Action) bool {
+		if a == popup.ActApply {
 			go func() {
 				f(row.Id())
 			}()
 			return true
 		}
 		return false
-	})
+	}, actions.Popup)
+	rlt.SetDoubleClickAction(popup.ActApply)
 	rlt.SetExtraRows(map[int]commander.Row{
 		0: commander.NewSimpleRow("", []string{"All Namespaces"}, true),
 	})
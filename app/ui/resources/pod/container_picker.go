@@ -2,9 +2,10 @@ package pod
 
 import (
 	"errors"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
 	"github.com/AnatolyRugalev/kube-commander/commander"
-	"github.com/gdamore/tcell"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -68,12 +69,13 @@ func newContainerPicker(pod v1.Pod, f ContainerFunc) *picker {
 		pod:       pod,
 		f:         f,
 	}
-	picker.BindOnKeyPress(picker.OnKeyPress)
+	picker.BindOnAction(picker.OnAction, actions.Popup)
+	picker.SetDoubleClickAction(popup.ActApply)
 	return picker
 }
 
-func (p *picker) OnKeyPress(row commander.Row, event *tcell.EventKey) bool {
-	if event.Key() == tcell.KeyEnter {
+func (p *picker) OnAction(row commander.Row, a *actions.Action) bool {
+	if a == popup.ActApply {
 		item, ok := row.(*item)
 		if ok {
 			go p.f(p.pod, item.container, item.status)
@@ -3,9 +3,9 @@ package pod
 import (
 	"context"
 	"errors"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
 	"github.com/AnatolyRugalev/kube-commander/commander"
-	"github.com/gdamore/tcell"
 	"k8s.io/api/core/v1"
 	"strings"
 )
@@ -23,28 +23,31 @@ func NewPodsList(workspace commander.Workspace, resource *commander.Resource, fo
 		workspace:         workspace,
 		resource:          resource,
 	}
-	pl.BindOnKeyPress(pl.OnKeyPress)
+	pl.BindOnAction(pl.OnAction, actions.Kind("Pod"))
 	return &pl
 }
 
-func (p PodsList) OnKeyPress(row commander.Row, event *tcell.EventKey) bool {
-	switch event.Key() {
+var (
+	actLogs         = actions.Register("pod.logs", "Show logs", actions.Kind("Pod"), "l")
+	actPreviousLogs = actions.Register("pod.previousLogs", "Show previous logs", actions.Kind("Pod"), "L")
+	actForward      = actions.Register("pod.forward", "Forward port", actions.Kind("Pod"), "f")
+	actShell        = actions.Register("pod.shell", "Shell into selected pod", actions.Kind("Pod"), "s")
+)
+
+func (p PodsList) OnAction(row commander.Row, a *actions.Action) bool {
+	switch a {
+	case actLogs:
+		go p.logs(row, false)
+	case actPreviousLogs:
+		go p.logs(row, true)
+	case actForward:
+		go p.forward(row)
+	case actShell:
+		go p.shell(row)
 	default:
-		switch event.Rune() {
-		case 'L':
-			go p.logs(row, true)
-		case 'l':
-			go p.logs(row, false)
-			return true
-		case 'f':
-			go p.forward(row)
-			return true
-		case 's':
-			go p.shell(row)
-			return true
-		}
+		return false
 	}
-	return false
+	return true
 }
 
 func (p PodsList) getPod(row commander.Row) (*v1.Pod, error) {
@@ -3,9 +3,10 @@ package pod
 import (
 	"errors"
 	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
 	"github.com/AnatolyRugalev/kube-commander/commander"
-	"github.com/gdamore/tcell"
 	v1 "k8s.io/api/core/v1"
 	"strconv"
 )
@@ -68,12 +69,13 @@ func newPortPicker(pod v1.Pod, f PortFunc) (*portPicker, error) {
 		pod:       pod,
 		f:         f,
 	}
-	picker.BindOnKeyPress(picker.OnKeyPress)
+	picker.BindOnAction(picker.OnAction, actions.Popup)
+	picker.SetDoubleClickAction(popup.ActApply)
 	return picker, nil
 }
 
-func (p *portPicker) OnKeyPress(row commander.Row, event *tcell.EventKey) bool {
-	if event.Key() == tcell.KeyEnter {
+func (p *portPicker) OnAction(row commander.Row, a *actions.Action) bool {
+	if a == popup.ActApply {
 		item, ok := row.(*portItem)
 		if ok {
 			go p.f(p.pod, item.container, item.port)
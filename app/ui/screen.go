@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/focus"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
 	"github.com/AnatolyRugalev/kube-commander/commander"
@@ -8,6 +9,12 @@ import (
 	"github.com/gdamore/tcell/views"
 )
 
+var (
+	actQuit          = actions.Register("global.quit", "Quit", actions.Global, "q")
+	actEditTheme     = actions.Register("global.editTheme", "Edit theme of the focused widget", actions.Global, "F10")
+	actStopEditTheme = actions.Register("global.stopEditTheme", "Stop editing theme", actions.Global, "F11")
+)
+
 type Screen struct {
 	*views.Panel
 	*focus.Focusable
@@ -41,6 +48,12 @@ func (s *Screen) UpdateScreen() {
 	}
 }
 
+func (s *Screen) PostFunc(f func()) {
+	if s.app != nil {
+		s.app.PostFunc(f)
+	}
+}
+
 func (s *Screen) SetWorkspace(workspace commander.Workspace) {
 	s.workspace = workspace
 	s.SetContent(s.workspace)
@@ -76,21 +89,26 @@ func (s Screen) HandleEvent(e tcell.Event) bool {
 	if s.BoxLayout.HandleEvent(e) {
 		return true
 	}
-	switch ev := e.(type) {
-	case *tcell.EventKey:
-		if ev.Rune() == 'q' && ev.Modifiers() == tcell.ModNone {
-			s.app.Quit()
-			return true
-		}
-		switch ev.Key() {
-		case tcell.KeyF10:
-			err := s.theme.Init()
-			if err != nil {
-				s.status.Error(err)
-			}
-		case tcell.KeyF11:
-			s.theme.DeInit()
-		}
+	if ev, ok := e.(*tcell.EventKey); ok {
+		return actions.HandleKey(ev, s.HandleAction, actions.Global)
 	}
 	return false
 }
+
+// HandleAction runs global actions of the screen
+func (s Screen) HandleAction(a *actions.Action) bool {
+	switch a {
+	case actQuit:
+		s.app.Quit()
+	case actEditTheme:
+		err := s.theme.Init()
+		if err != nil {
+			s.status.Error(err)
+		}
+	case actStopEditTheme:
+		s.theme.DeInit()
+	default:
+		return false
+	}
+	return true
+}
@@ -3,10 +3,25 @@ package theme
 import (
 	"errors"
 	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 )
 
+var (
+	actPrevComponent = actions.Register("theme.prevComponent", "Previous component", actions.Theme, "PgUp")
+	actNextComponent = actions.Register("theme.nextComponent", "Next component", actions.Theme, "PgDn")
+	actPrevBg        = actions.Register("theme.prevBackground", "Previous background color", actions.Theme, "Up")
+	actNextBg        = actions.Register("theme.nextBackground", "Next background color", actions.Theme, "Down")
+	actPrevFg        = actions.Register("theme.prevForeground", "Previous foreground color", actions.Theme, "Left")
+	actNextFg        = actions.Register("theme.nextForeground", "Next foreground color", actions.Theme, "Right")
+	actBold          = actions.Register("theme.bold", "Switch bold", actions.Theme, "b")
+	actBlink         = actions.Register("theme.blink", "Switch blink", actions.Theme, "l")
+	actReverse       = actions.Register("theme.reverse", "Switch reverse", actions.Theme, "r")
+	actUnderline     = actions.Register("theme.underline", "Switch underline", actions.Theme, "u")
+	actDim           = actions.Register("theme.dim", "Switch dim", actions.Theme, "d")
+)
+
 type manager struct {
 	focus    commander.FocusManager
 	updater  commander.ScreenUpdater
@@ -57,46 +72,33 @@ func (m *manager) HandleEvent(e tcell.Event) bool {
 	if !ok {
 		return false
 	}
-	switch ev.Key() {
-	case tcell.KeyPgUp:
+	switch {
+	case actPrevComponent.Matches(ev):
 		m.PrevComponent()
-		return true
-	case tcell.KeyPgDn:
+	case actNextComponent.Matches(ev):
 		m.NextComponent()
-		return true
-	case tcell.KeyUp:
+	case actPrevBg.Matches(ev):
 		m.PrevBg()
-		return true
-	case tcell.KeyDown:
+	case actNextBg.Matches(ev):
 		m.NextBg()
-		return true
-	case tcell.KeyLeft:
+	case actPrevFg.Matches(ev):
 		m.PrevFg()
-		return true
-	case tcell.KeyRight:
+	case actNextFg.Matches(ev):
 		m.NextFg()
-		return true
-	}
-
-	switch ev.Rune() {
-	case 'b':
+	case actBold.Matches(ev):
 		m.SwitchAttr(tcell.AttrBold)
-		return true
-	case 'l':
+	case actBlink.Matches(ev):
 		m.SwitchAttr(tcell.AttrBlink)
-		return true
-	case 'r':
+	case actReverse.Matches(ev):
 		m.SwitchAttr(tcell.AttrReverse)
-		return true
-	case 'u':
+	case actUnderline.Matches(ev):
 		m.SwitchAttr(tcell.AttrUnderline)
-		return true
-	case 'd':
+	case actDim.Matches(ev):
 		m.SwitchAttr(tcell.AttrDim)
-		return true
+	default:
+		return false
 	}
-
-	return false
+	return true
 }
 
 func (m *manager) NextComponent() {
@@ -0,0 +1,161 @@
+package input
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+)
+
+// Input is a single line text editor
+type Input struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view   views.View
+	label  string
+	text   []rune
+	cursor int
+	width  int
+
+	stLabel      commander.StyleComponent
+	stText       commander.StyleComponent
+	stTextActive commander.StyleComponent
+}
+
+func NewInput(label string, text string, width int) *Input {
+	i := &Input{
+		Focusable: focus.NewFocusable(),
+		label:     label,
+		width:     width,
+
+		stLabel:      theme.NewComponent("label", theme.Default),
+		stText:       theme.NewComponent("text", theme.Default.Background(theme.ColorSelectedUnfocusedBackground)),
+		stTextActive: theme.NewComponent("text-active", theme.Default.Background(theme.ColorSelectedFocusedBackground)),
+	}
+	i.SetText(text)
+	return i
+}
+
+func (i *Input) GetComponents() []commander.StyleComponent {
+	return []commander.StyleComponent{
+		i.stLabel,
+		i.stText,
+		i.stTextActive,
+	}
+}
+
+func (i *Input) Text() string {
+	return string(i.text)
+}
+
+// SetText replaces input contents and moves cursor to the end
+func (i *Input) SetText(text string) {
+	i.text = []rune(text)
+	i.cursor = len(i.text)
+}
+
+func (i *Input) Draw() {
+	if i.view == nil {
+		return
+	}
+	w, _ := i.view.Size()
+	x := 0
+	for _, ch := range i.label {
+		i.view.SetContent(x, 0, ch, nil, i.stLabel.Style())
+		x += runewidth.RuneWidth(ch)
+	}
+	style := i.stText.Style()
+	if i.IsFocused() {
+		style = i.stTextActive.Style()
+	}
+	available := w - x
+	if available <= 0 {
+		return
+	}
+	// Scroll text horizontally to keep cursor visible
+	start := 0
+	for runewidth.StringWidth(string(i.text[start:i.cursor])) >= available {
+		start++
+	}
+	for pos := x; pos < w; pos++ {
+		i.view.SetContent(pos, 0, ' ', nil, style)
+	}
+	for idx := start; idx <= len(i.text); idx++ {
+		ch := ' '
+		if idx < len(i.text) {
+			ch = i.text[idx]
+		}
+		chWidth := runewidth.RuneWidth(ch)
+		if x+chWidth > w {
+			break
+		}
+		st := style
+		if idx == i.cursor && i.IsFocused() {
+			st = st.Reverse(true)
+		}
+		i.view.SetContent(x, 0, ch, nil, st)
+		x += chWidth
+	}
+}
+
+func (i *Input) Resize() {
+}
+
+func (i *Input) HandleEvent(ev tcell.Event) bool {
+	e, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	switch e.Key() {
+	case tcell.KeyLeft:
+		if i.cursor > 0 {
+			i.cursor--
+		}
+		return true
+	case tcell.KeyRight:
+		if i.cursor < len(i.text) {
+			i.cursor++
+		}
+		return true
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		i.cursor = 0
+		return true
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		i.cursor = len(i.text)
+		return true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if i.cursor > 0 {
+			i.text = append(i.text[:i.cursor-1], i.text[i.cursor:]...)
+			i.cursor--
+		}
+		return true
+	case tcell.KeyDelete:
+		if i.cursor < len(i.text) {
+			i.text = append(i.text[:i.cursor], i.text[i.cursor+1:]...)
+		}
+		return true
+	case tcell.KeyCtrlU:
+		i.SetText("")
+		return true
+	case tcell.KeyRune:
+		i.text = append(i.text[:i.cursor], append([]rune{e.Rune()}, i.text[i.cursor:]...)...)
+		i.cursor++
+		return true
+	}
+	return false
+}
+
+func (i *Input) SetView(view views.View) {
+	i.view = view
+}
+
+func (i *Input) Size() (int, int) {
+	return runewidth.StringWidth(i.label) + i.width, 1
+}
+
+func (i *Input) MaxSize() (int, int) {
+	return i.Size()
+}
@@ -0,0 +1,72 @@
+package listTable
+
+import "github.com/AnatolyRugalev/kube-commander/app/actions"
+
+// Navigation actions, shared with other list-like widgets
+var (
+	ActUp          = actions.Register("list.up", "Select previous row", actions.List, "Up")
+	ActDown        = actions.Register("list.down", "Select next row", actions.List, "Down")
+	ActPageUp      = actions.Register("list.pageUp", "Previous page", actions.List, "PgUp")
+	ActPageDown    = actions.Register("list.pageDown", "Next page", actions.List, "PgDn")
+	ActHome        = actions.Register("list.home", "Select first row", actions.List, "Home")
+	ActEnd         = actions.Register("list.end", "Select last row", actions.List, "End")
+	ActLeft        = actions.Register("list.left", "Scroll left", actions.List, "Left")
+	ActRight       = actions.Register("list.right", "Scroll right", actions.List, "Right")
+	ActFilter      = actions.Register("list.filter", "Filter rows", actions.List, "/")
+	ActClearFilter = actions.Register("list.clearFilter", "Clear filter", actions.List, "Esc")
+)
+
+var (
+	actSort = actions.Register("list.sort", "Cycle sort column and direction", actions.List, "F6")
+
+	actMark        = actions.Register("marks.toggle", "Mark row", actions.Marks, "Space")
+	actMarkMatched = actions.Register("marks.matched", "Mark rows matching filter", actions.Marks, "+")
+	actInvertMarks = actions.Register("marks.invert", "Invert marks", actions.Marks, "*")
+	actClearMarks  = actions.Register("marks.clear", "Clear marks", actions.Marks, "-")
+
+	actDescribe = actions.Register("resource.describe", "Describe selected resource", actions.Resource, "d")
+	actEdit     = actions.Register("resource.edit", "Edit selected resource", actions.Resource, "e")
+	actCopy     = actions.Register("resource.copy", "Copy resource names to the clipboard", actions.Resource, "c")
+	actDelete   = actions.Register("resource.delete", "Delete resources", actions.Resource, "Delete")
+	actRestart  = actions.Register("resource.restart", "Restart deployments, stateful sets and daemon sets", actions.Resource, "r")
+	actColumns  = actions.Register("resource.columns", "Choose columns", actions.Resource, "F5")
+	actSelector = actions.Register("resource.selector", "Set label and field selectors", actions.Resource, "F4")
+	actFilters  = actions.Register("resource.filters", "Save current filter or recall a saved one", actions.Resource, "F7")
+	actLabels   = actions.Register("resource.labels", "Set or remove labels", actions.Resource, "F8")
+	actExport   = actions.Register("resource.export", "Export table to a file or the clipboard", actions.Resource, "F9")
+	actRefresh  = actions.Register("resource.refresh", "Reload resources", actions.Resource, "Ctrl+R")
+
+	actToggleColumn = actions.Register("columns.toggle", "Show or hide column", actions.Popup, "Space")
+	actWideColumns  = actions.Register("columns.wide", "Switch between main and all columns", actions.Popup, "w", "W")
+	actColumnUp     = actions.Register("columns.moveUp", "Move column up", actions.Popup, "Shift+Up", "-")
+	actColumnDown   = actions.Register("columns.moveDown", "Move column down", actions.Popup, "Shift+Down", "+")
+)
+
+func (lt *ListTable) ActionScopes() []actions.Scope {
+	if lt.format.Has(WithMarks) {
+		return []actions.Scope{actions.Marks, actions.List}
+	}
+	return []actions.Scope{actions.List}
+}
+
+func (r *ResourceListTable) ActionScopes() []actions.Scope {
+	scopes := r.ListTable.ActionScopes()
+	if r.format.Has(NoActions) {
+		return scopes
+	}
+	return append([]actions.Scope{actions.Resource, actions.Kind(r.resource.Gvk.Kind)}, scopes...)
+}
+
+// ActionEnabled hides actions which need a selected or marked row when there is none
+func (r *ResourceListTable) ActionEnabled(a *actions.Action) bool {
+	switch a {
+	case actRestart:
+		return restartableKinds[r.resource.Gk] && len(r.Targets()) > 0
+	case actDescribe, actEdit, actCopy, actDelete, actLabels:
+		return len(r.Targets()) > 0
+	}
+	if a.Scope == actions.Kind(r.resource.Gvk.Kind) {
+		return r.SelectedRow() != nil
+	}
+	return true
+}
@@ -0,0 +1,395 @@
+package listTable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/atotto/clipboard"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"strings"
+	"time"
+)
+
+const (
+	// Number of object names listed in confirmation
+	bulkConfirmNames = 5
+	labelInputWidth  = 40
+)
+
+// Kinds which are restarted by changing pod template, like kubectl rollout restart does
+var restartableKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:  true,
+	{Group: "apps", Kind: "StatefulSet"}: true,
+	{Group: "apps", Kind: "DaemonSet"}:   true,
+}
+
+type bulkAction func(md *metav1.PartialObjectMetadata) error
+
+// targets returns metadata of marked rows or the selected row
+func (r *ResourceListTable) targets() []*metav1.PartialObjectMetadata {
+	var targets []*metav1.PartialObjectMetadata
+	for _, row := range r.Targets() {
+		if md, err := r.RowMetadata(row); err == nil {
+			targets = append(targets, md)
+		}
+	}
+	return targets
+}
+
+func (r *ResourceListTable) displayName(md *metav1.PartialObjectMetadata) string {
+	if r.resource.Namespaced {
+		return md.Namespace + "/" + md.Name
+	}
+	return md.Name
+}
+
+// describeTargets summarizes targets for confirmation, like "Pod default/nginx" or "3 Pods: a, b, c"
+func (r *ResourceListTable) describeTargets(targets []*metav1.PartialObjectMetadata) string {
+	if len(targets) == 1 {
+		return r.resource.Gvk.Kind + " " + r.displayName(targets[0])
+	}
+	var names []string
+	for i, md := range targets {
+		if i == bulkConfirmNames {
+			names = append(names, fmt.Sprintf("and %d more", len(targets)-bulkConfirmNames))
+			break
+		}
+		names = append(names, r.displayName(md))
+	}
+	return fmt.Sprintf("%d %ss: %s", len(targets), r.resource.Gvk.Kind, strings.Join(names, ", "))
+}
+
+// runBulk asks for confirmation and applies action to every target. Result of a single object is reported
+// in the status line, otherwise the report shows progress and result of every object
+func (r *ResourceListTable) runBulk(verb string, targets []*metav1.PartialObjectMetadata, action bulkAction) {
+	if len(targets) == 0 {
+		return
+	}
+	status := r.container.Status()
+	if !status.Confirm(fmt.Sprintf("You are about to %s %s. Are you sure? (y/N)", strings.ToLower(verb), r.describeTargets(targets))) {
+		status.Info("Cancelled.")
+		return
+	}
+	updater := r.container.ScreenUpdater()
+	defer updater.PostFunc(r.ClearMarks)
+	if len(targets) == 1 {
+		if err := action(targets[0]); err != nil {
+			status.Error(err)
+		} else {
+			status.Info("Done.")
+		}
+		return
+	}
+	var names []string
+	for _, md := range targets {
+		names = append(names, r.displayName(md))
+	}
+	report := newBulkReport(verb, names)
+	updater.PostFunc(func() {
+		r.container.ShowPopup(verb, report)
+	})
+	failed := 0
+	for i, md := range targets {
+		i, err := i, action(md)
+		if err != nil {
+			failed++
+		}
+		updater.PostFunc(func() {
+			report.SetResult(i, err)
+			updater.UpdateScreen()
+		})
+	}
+	if failed > 0 {
+		status.Warning(fmt.Sprintf("%s: %d of %d failed", verb, failed, len(targets)))
+	} else {
+		status.Info(fmt.Sprintf("%s: %d done", verb, len(targets)))
+	}
+}
+
+func (r *ResourceListTable) bulkDelete(targets []*metav1.PartialObjectMetadata) {
+	r.runBulk("Delete", targets, func(md *metav1.PartialObjectMetadata) error {
+		return r.container.Client().Delete(context.TODO(), r.resource, md.Namespace, md.Name)
+	})
+}
+
+// bulkRestart restarts workloads by updating pod template annotation
+func (r *ResourceListTable) bulkRestart(targets []*metav1.PartialObjectMetadata) {
+	if !restartableKinds[r.resource.Gk] {
+		r.container.Status().Warning(fmt.Sprintf("%s can't be restarted", r.resource.Gvk.Kind))
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						"kubectl.kubernetes.io/restartedAt": time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		r.container.Status().Error(err)
+		return
+	}
+	r.runBulk("Restart", targets, func(md *metav1.PartialObjectMetadata) error {
+		return r.container.Client().Patch(context.TODO(), r.resource, md.Namespace, md.Name, types.MergePatchType, patch)
+	})
+}
+
+// parseLabels parses labels like kubectl label does: "app=web tier=backend" sets labels and "app-" removes one
+func parseLabels(str string) (map[string]interface{}, error) {
+	labels := make(map[string]interface{})
+	for _, item := range strings.Fields(str) {
+		if strings.HasSuffix(item, "-") && !strings.Contains(item, "=") {
+			labels[strings.TrimSuffix(item, "-")] = nil
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value or key-", item)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("no labels given")
+	}
+	return labels, nil
+}
+
+func (r *ResourceListTable) bulkLabel(targets []*metav1.PartialObjectMetadata, str string) {
+	labels, err := parseLabels(str)
+	if err != nil {
+		r.container.Status().Error(err)
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		r.container.Status().Error(err)
+		return
+	}
+	r.runBulk("Label", targets, func(md *metav1.PartialObjectMetadata) error {
+		return r.container.Client().Patch(context.TODO(), r.resource, md.Namespace, md.Name, types.MergePatchType, patch)
+	})
+}
+
+func (r *ResourceListTable) pickLabels(targets []*metav1.PartialObjectMetadata) {
+	if len(targets) == 0 {
+		return
+	}
+	prompt := newLabelPrompt(func(labels string) {
+		r.container.FocusManager().Blur()
+		go r.bulkLabel(targets, labels)
+	})
+	r.container.ShowPopup("Labels: key=value or key-", prompt)
+}
+
+func (r *ResourceListTable) bulkCopy(targets []*metav1.PartialObjectMetadata) {
+	if len(targets) == 0 {
+		return
+	}
+	var names []string
+	for _, md := range targets {
+		names = append(names, md.Name)
+	}
+	err := clipboard.WriteAll(strings.Join(names, "\n"))
+	if err != nil {
+		r.container.Status().Error(err)
+		return
+	}
+	if len(names) == 1 {
+		r.container.Status().Info(fmt.Sprintf("Resource name copied! '%s'", names[0]))
+	} else {
+		r.container.Status().Info(fmt.Sprintf("%d resource names copied!", len(names)))
+	}
+}
+
+// labelPrompt asks for labels to set or remove
+type labelPrompt struct {
+	*input.Input
+	apply func(labels string)
+}
+
+func newLabelPrompt(apply func(labels string)) *labelPrompt {
+	p := &labelPrompt{
+		Input: input.NewInput("", "", labelInputWidth),
+		apply: apply,
+	}
+	p.Input.OnFocus()
+	return p
+}
+
+func (p *labelPrompt) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventKey); ok && popup.ActApply.Matches(e) {
+		p.apply(p.Text())
+		return true
+	}
+	return p.Input.HandleEvent(ev)
+}
+
+type bulkResult struct {
+	name string
+	done bool
+	err  error
+}
+
+// bulkReport shows progress and result of a bulk action for every object
+type bulkReport struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view    views.View
+	verb    string
+	results []bulkResult
+	top     int
+
+	stPending commander.StyleComponent
+	stDone    commander.StyleComponent
+	stFailed  commander.StyleComponent
+}
+
+func newBulkReport(verb string, names []string) *bulkReport {
+	b := &bulkReport{
+		Focusable: focus.NewFocusable(),
+		verb:      verb,
+
+		stPending: theme.NewComponent("pending", theme.Default),
+		stDone:    theme.NewComponent("done", theme.Default.Foreground(tcell.ColorDarkGreen)),
+		stFailed:  theme.NewComponent("failed", theme.Default.Foreground(tcell.ColorDarkRed)),
+	}
+	for _, name := range names {
+		b.results = append(b.results, bulkResult{name: name})
+	}
+	return b
+}
+
+func (b *bulkReport) GetComponents() []commander.StyleComponent {
+	return []commander.StyleComponent{
+		b.stPending,
+		b.stDone,
+		b.stFailed,
+	}
+}
+
+// SetResult records result of the object
+func (b *bulkReport) SetResult(index int, err error) {
+	b.results[index].done = true
+	b.results[index].err = err
+}
+
+func (b *bulkReport) summary() string {
+	done, failed := 0, 0
+	for _, result := range b.results {
+		if result.done {
+			done++
+		}
+		if result.err != nil {
+			failed++
+		}
+	}
+	return fmt.Sprintf("%s: %d of %d processed, %d failed", b.verb, done, len(b.results), failed)
+}
+
+func (b *bulkReport) line(result bulkResult) (string, tcell.Style) {
+	switch {
+	case !result.done:
+		return "… " + result.name, b.stPending.Style()
+	case result.err != nil:
+		return "✗ " + result.name + ": " + result.err.Error(), b.stFailed.Style()
+	}
+	return "✓ " + result.name, b.stDone.Style()
+}
+
+func (b *bulkReport) Draw() {
+	b.view.Fill(' ', theme.Default)
+	b.drawLine(0, b.summary(), theme.Default.Bold(true))
+	_, h := b.view.Size()
+	for y := 1; y < h; y++ {
+		i := b.top + y - 1
+		if i >= len(b.results) {
+			break
+		}
+		str, style := b.line(b.results[i])
+		b.drawLine(y, str, style)
+	}
+}
+
+func (b *bulkReport) drawLine(y int, str string, style tcell.Style) {
+	x := 0
+	for _, ch := range str {
+		b.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+}
+
+func (b *bulkReport) Resize() {
+}
+
+func (b *bulkReport) scroll(delta int) {
+	_, h := b.view.Size()
+	b.top += delta
+	if max := len(b.results) - (h - 1); b.top > max {
+		b.top = max
+	}
+	if b.top < 0 {
+		b.top = 0
+	}
+}
+
+func (b *bulkReport) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventMouse); ok {
+		switch {
+		case e.Buttons()&tcell.WheelDown != 0:
+			b.scroll(wheelRows)
+			return true
+		case e.Buttons()&tcell.WheelUp != 0:
+			b.scroll(-wheelRows)
+			return true
+		}
+	}
+	if e, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case ActDown.Matches(e):
+			b.scroll(1)
+			return true
+		case ActUp.Matches(e):
+			b.scroll(-1)
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bulkReport) SetView(view views.View) {
+	b.view = view
+}
+
+func (b *bulkReport) Size() (int, int) {
+	return b.MaxSize()
+}
+
+func (b *bulkReport) MaxSize() (int, int) {
+	w := runewidth.StringWidth(b.summary())
+	for _, result := range b.results {
+		// Reserve space for error messages which arrive later
+		if width := runewidth.StringWidth(result.name) + 40; width > w {
+			w = width
+		}
+	}
+	return w, len(b.results) + 1
+}
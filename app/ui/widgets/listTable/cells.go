@@ -0,0 +1,114 @@
+package listTable
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+const ellipsis = "…"
+
+// maxColumnWidth returns the maximum width of the column, 0 means no limit
+func maxColumnWidth(name string) int {
+	if width, ok := MaxColumnWidths[name]; ok {
+		return width
+	}
+	return MaxColumnWidths[DefaultColumnWidthKey]
+}
+
+// cellWriter draws runes at display cells of a line. Line is shifted left by horizontal scroll,
+// so runes to the left of the view and to the right of it are clipped
+type cellWriter struct {
+	lt    *ListTable
+	y     int
+	shift int
+	width int
+
+	// Rune is drawn when the next one arrives, so zero-width runes could be combined with it
+	pending bool
+	x       int
+	mainc   rune
+	combc   []rune
+	runeW   int
+	style   tcell.Style
+}
+
+func (lt *ListTable) newCellWriter(y int) *cellWriter {
+	return &cellWriter{
+		lt:    lt,
+		y:     y,
+		shift: lt.leftCell,
+		width: lt.viewWidth(),
+	}
+}
+
+// put places rune at x and returns the next position
+func (w *cellWriter) put(x int, ch rune, style tcell.Style) int {
+	width := runewidth.RuneWidth(ch)
+	if width == 0 && w.pending {
+		w.combc = append(w.combc, ch)
+		return x
+	}
+	w.flush()
+	if width == 0 {
+		// Nothing to combine with
+		return x
+	}
+	w.pending, w.x, w.mainc, w.combc, w.runeW, w.style = true, x, ch, nil, width, style
+	return x + width
+}
+
+func (w *cellWriter) flush() {
+	if !w.pending {
+		return
+	}
+	w.pending = false
+	x := w.x - w.shift
+	if x < 0 || x+w.runeW > w.width {
+		// Wide rune cut by the view edge is replaced with spaces
+		for i := 0; i < w.runeW; i++ {
+			if x+i >= 0 && x+i < w.width {
+				w.lt.view.SetContent(x+i, w.y, ' ', nil, w.style)
+			}
+		}
+		return
+	}
+	w.lt.view.SetContent(x, w.y, w.mainc, w.combc, w.style)
+}
+
+// cell draws value padded or truncated with ellipsis to the size. Highlighted byte ranges of the value are drawn
+// with match style
+func (w *cellWriter) cell(x int, value string, size int, style tcell.Style, matchStyle tcell.Style, highlights [][]int) int {
+	end := x + size
+	limit := end
+	if runewidth.StringWidth(value) > size {
+		limit = end - runewidth.StringWidth(ellipsis)
+	}
+	for b, ch := range value {
+		if x+runewidth.RuneWidth(ch) > limit {
+			break
+		}
+		st := style
+		if inRanges(highlights, b) {
+			st = matchStyle
+		}
+		x = w.put(x, ch, st)
+	}
+	if limit < end && limit >= x {
+		for _, ch := range ellipsis {
+			x = w.put(x, ch, style)
+		}
+	}
+	for x < end {
+		x = w.put(x, ' ', style)
+	}
+	return x
+}
+
+func inRanges(ranges [][]int, b int) bool {
+	for _, r := range ranges {
+		if b >= r[0] && b < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ColumnLayout describes which columns of a resource kind are shown and in which order
+type ColumnLayout struct {
+	// Show all columns instead of priority-0 ones only
+	Wide bool `json:"wide"`
+	// Columns listed here go first, the rest keep their original order
+	Order  []string `json:"order,omitempty"`
+	Hidden []string `json:"hidden,omitempty"`
+}
+
+func layoutKey(gk schema.GroupKind) string {
+	return "columns/" + gk.String()
+}
+
+// order returns indexes of all columns in layout order
+func (l ColumnLayout) order(definitions []metav1.TableColumnDefinition) []int {
+	var ids []int
+	listed := make(map[string]bool)
+	for _, name := range l.Order {
+		for i, definition := range definitions {
+			if definition.Name == name && !listed[name] {
+				ids = append(ids, i)
+				listed[name] = true
+			}
+		}
+	}
+	for i, definition := range definitions {
+		if !listed[definition.Name] {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+func (l ColumnLayout) isHidden(name string) bool {
+	for _, hidden := range l.Hidden {
+		if hidden == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Columns returns indexes of columns to show
+func (l ColumnLayout) Columns(definitions []metav1.TableColumnDefinition) []int {
+	var ids []int
+	for _, i := range l.order(definitions) {
+		definition := definitions[i]
+		if l.isHidden(definition.Name) || (!l.Wide && definition.Priority != 0) {
+			continue
+		}
+		ids = append(ids, i)
+	}
+	return ids
+}
+
+type LayoutFunc func(layout ColumnLayout)
+
+type columnItem struct {
+	definition metav1.TableColumnDefinition
+	hidden     bool
+}
+
+// columnChooser is a popup to show, hide and reorder columns
+type columnChooser struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view    views.View
+	items   []columnItem
+	wide    bool
+	current int
+	apply   LayoutFunc
+
+	stColumn   commander.StyleComponent
+	stHidden   commander.StyleComponent
+	stSelected commander.StyleComponent
+	stHint     commander.StyleComponent
+}
+
+func columnChooserHint() string {
+	return actions.Hints(
+		actToggleColumn.Hint("show/hide"),
+		actColumnUp.Hint("up"),
+		actColumnDown.Hint("down"),
+		actWideColumns.Hint("all columns"),
+		popup.ActApply.Hint("apply"),
+	)
+}
+
+func newColumnChooser(definitions []metav1.TableColumnDefinition, layout ColumnLayout, apply LayoutFunc) *columnChooser {
+	c := &columnChooser{
+		Focusable: focus.NewFocusable(),
+		wide:      layout.Wide,
+		apply:     apply,
+
+		stColumn:   theme.NewComponent("column", theme.Default),
+		stHidden:   theme.NewComponent("column-hidden", theme.Default.Foreground(theme.ColorDisabledForeground)),
+		stSelected: theme.NewComponent("column-selected", theme.Default.Background(theme.ColorSelectedFocusedBackground)),
+		stHint:     theme.NewComponent("hint", theme.Default.Underline(true)),
+	}
+	for _, i := range layout.order(definitions) {
+		c.items = append(c.items, columnItem{
+			definition: definitions[i],
+			hidden:     layout.isHidden(definitions[i].Name),
+		})
+	}
+	return c
+}
+
+func (c *columnChooser) GetComponents() []commander.StyleComponent {
+	return []commander.StyleComponent{
+		c.stColumn,
+		c.stHidden,
+		c.stSelected,
+		c.stHint,
+	}
+}
+
+func (c *columnChooser) layout() ColumnLayout {
+	layout := ColumnLayout{Wide: c.wide}
+	for _, item := range c.items {
+		layout.Order = append(layout.Order, item.definition.Name)
+		if item.hidden {
+			layout.Hidden = append(layout.Hidden, item.definition.Name)
+		}
+	}
+	return layout
+}
+
+func (c *columnChooser) Draw() {
+	c.view.Fill(' ', theme.Default)
+	mode := "Columns: priority 0"
+	if c.wide {
+		mode = "Columns: all"
+	}
+	c.drawLine(0, mode, c.stColumn.Style().Bold(true))
+	for i, item := range c.items {
+		mark := "[x] "
+		if item.hidden {
+			mark = "[ ] "
+		}
+		style := c.stColumn.Style()
+		if item.hidden || (!c.wide && item.definition.Priority != 0) {
+			style = c.stHidden.Style()
+		}
+		if i == c.current {
+			style = c.stSelected.Style()
+		}
+		c.drawLine(i+1, mark+item.definition.Name, style)
+	}
+	c.drawLine(len(c.items)+2, columnChooserHint(), c.stHint.Style())
+}
+
+func (c *columnChooser) drawLine(y int, str string, style tcell.Style) {
+	x := 0
+	for _, ch := range str {
+		c.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+}
+
+func (c *columnChooser) Resize() {
+}
+
+func (c *columnChooser) move(delta int) {
+	target := c.current + delta
+	if target < 0 || target >= len(c.items) {
+		return
+	}
+	c.items[c.current], c.items[target] = c.items[target], c.items[c.current]
+	c.current = target
+}
+
+func (c *columnChooser) selectIndex(index int) {
+	if index < 0 || index >= len(c.items) {
+		return
+	}
+	c.current = index
+}
+
+// click selects the column under the mouse. Click on the checkbox shows or hides the column
+func (c *columnChooser) click(ev *tcell.EventMouse) bool {
+	local, ok := mouse.Local(ev, c.view)
+	if !ok || local.Buttons()&tcell.Button1 == 0 {
+		return ok
+	}
+	x, y := local.Position()
+	index := y - 1
+	if index < 0 || index >= len(c.items) {
+		return true
+	}
+	c.selectIndex(index)
+	if x < 3 {
+		c.items[index].hidden = !c.items[index].hidden
+	}
+	return true
+}
+
+func (c *columnChooser) HandleEvent(ev tcell.Event) bool {
+	if m, ok := ev.(*tcell.EventMouse); ok {
+		return c.click(m)
+	}
+	e, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	switch {
+	case popup.ActApply.Matches(e):
+		c.apply(c.layout())
+	case actColumnUp.Matches(e):
+		c.move(-1)
+	case actColumnDown.Matches(e):
+		c.move(1)
+	case ActUp.Matches(e):
+		c.selectIndex(c.current - 1)
+	case ActDown.Matches(e):
+		c.selectIndex(c.current + 1)
+	case actToggleColumn.Matches(e):
+		if len(c.items) > 0 {
+			c.items[c.current].hidden = !c.items[c.current].hidden
+		}
+	case actWideColumns.Matches(e):
+		c.wide = !c.wide
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *columnChooser) SetView(view views.View) {
+	c.view = view
+}
+
+func (c *columnChooser) Size() (int, int) {
+	return c.MaxSize()
+}
+
+func (c *columnChooser) MaxSize() (int, int) {
+	w := runewidth.StringWidth(columnChooserHint())
+	for _, item := range c.items {
+		if width := runewidth.StringWidth(item.definition.Name) + 4; width > w {
+			w = width
+		}
+	}
+	return w, len(c.items) + 3
+}
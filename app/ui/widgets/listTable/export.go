@@ -0,0 +1,250 @@
+package listTable
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+	"strings"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV      ExportFormat = "CSV"
+	ExportTSV      ExportFormat = "TSV"
+	ExportJSON     ExportFormat = "JSON"
+	ExportMarkdown ExportFormat = "Markdown"
+
+	exportInputWidth = 40
+)
+
+var ExportFormats = []ExportFormat{ExportCSV, ExportTSV, ExportJSON, ExportMarkdown}
+
+// Extension returns file extension of the format
+func (f ExportFormat) Extension() string {
+	if f == ExportMarkdown {
+		return ".md"
+	}
+	return "." + strings.ToLower(string(f))
+}
+
+// ExportTable returns visible columns and raw cell values of rows matching the filter, in table order
+func (lt *ListTable) ExportTable() ([]string, [][]string) {
+	var rows [][]string
+	lt.rows.Each(func(n *rowNode) {
+		if !n.matched {
+			return
+		}
+		cells := n.row.Cells()
+		values := make([]string, len(lt.colIds))
+		for colId, cellId := range lt.colIds {
+			if cellId < len(cells) {
+				values[colId] = cells[cellId]
+			}
+		}
+		rows = append(rows, values)
+	})
+	return lt.columns, rows
+}
+
+// Export renders visible part of the table in the format
+func (lt *ListTable) Export(format ExportFormat) ([]byte, error) {
+	headers, rows := lt.ExportTable()
+	return Export(format, headers, rows)
+}
+
+// Export renders table in the format
+func Export(format ExportFormat, headers []string, rows [][]string) ([]byte, error) {
+	switch format {
+	case ExportCSV:
+		return exportCSV(',', headers, rows)
+	case ExportTSV:
+		return exportCSV('\t', headers, rows)
+	case ExportJSON:
+		return exportJSON(headers, rows)
+	case ExportMarkdown:
+		return exportMarkdown(headers, rows), nil
+	}
+	return nil, fmt.Errorf("unknown export format %s", format)
+}
+
+func exportCSV(comma rune, headers []string, rows [][]string) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+	if err := w.Write(headers); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportJSON renders an array of objects keyed by column. Keys keep the column order
+func exportJSON(headers []string, rows [][]string) ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for colId, header := range headers {
+			if colId > 0 {
+				buf.WriteString(", ")
+			}
+			key, err := json.Marshal(header)
+			if err != nil {
+				return nil, err
+			}
+			value, err := json.Marshal(row[colId])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(key)
+			buf.WriteString(": ")
+			buf.Write(value)
+		}
+		buf.WriteString("}")
+	}
+	if len(rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	return buf.Bytes(), nil
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func exportMarkdown(headers []string, rows [][]string) []byte {
+	buf := bytes.Buffer{}
+	writeRow := func(values []string) {
+		buf.WriteString("|")
+		for _, value := range values {
+			buf.WriteString(" " + markdownEscaper.Replace(value) + " |")
+		}
+		buf.WriteString("\n")
+	}
+	writeRow(headers)
+	buf.WriteString("|")
+	for range headers {
+		buf.WriteString("---|")
+	}
+	buf.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return buf.Bytes()
+}
+
+func exportHint() string {
+	return actions.Hints(popup.ActNext.Hint("format"), popup.ActApply.Hint("export"), "Empty file: clipboard")
+}
+
+// ExportFunc exports the table in the format to the file. Empty file means clipboard
+type ExportFunc func(format ExportFormat, file string)
+
+// exportPrompt is a popup to pick export format and destination
+type exportPrompt struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view   views.View
+	format int
+	file   *input.Input
+	apply  ExportFunc
+
+	stFormat         commander.StyleComponent
+	stFormatSelected commander.StyleComponent
+	stHint           commander.StyleComponent
+}
+
+func newExportPrompt(apply ExportFunc) *exportPrompt {
+	p := &exportPrompt{
+		Focusable: focus.NewFocusable(),
+		file:      input.NewInput("File: ", "", exportInputWidth),
+		apply:     apply,
+
+		stFormat:         theme.NewComponent("format", theme.Default),
+		stFormatSelected: theme.NewComponent("format-selected", theme.Default.Background(theme.ColorSelectedFocusedBackground)),
+		stHint:           theme.NewComponent("hint", theme.Default.Underline(true)),
+	}
+	p.file.OnFocus()
+	return p
+}
+
+func (p *exportPrompt) GetComponents() []commander.StyleComponent {
+	return append(p.file.GetComponents(), p.stFormat, p.stFormatSelected, p.stHint)
+}
+
+func (p *exportPrompt) Draw() {
+	p.view.Fill(' ', theme.Default)
+	x := p.drawText(0, 0, "Format: ", p.stFormat.Style())
+	for i, format := range ExportFormats {
+		style := p.stFormat.Style()
+		if i == p.format {
+			style = p.stFormatSelected.Style()
+		}
+		x = p.drawText(x, 0, string(format), style)
+		x = p.drawText(x, 0, " ", p.stFormat.Style())
+	}
+	p.file.Draw()
+	p.drawText(0, 3, exportHint(), p.stHint.Style())
+}
+
+func (p *exportPrompt) drawText(x int, y int, str string, style tcell.Style) int {
+	for _, ch := range str {
+		p.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+	return x
+}
+
+func (p *exportPrompt) Resize() {
+}
+
+func (p *exportPrompt) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case popup.ActApply.Matches(e):
+			p.apply(ExportFormats[p.format], p.file.Text())
+			return true
+		case popup.ActNext.Matches(e):
+			p.format = (p.format + 1) % len(ExportFormats)
+			return true
+		case popup.ActPrev.Matches(e):
+			p.format = (p.format + len(ExportFormats) - 1) % len(ExportFormats)
+			return true
+		}
+	}
+	return p.file.HandleEvent(ev)
+}
+
+func (p *exportPrompt) SetView(view views.View) {
+	p.view = view
+	w, _ := view.Size()
+	p.file.SetView(views.NewViewPort(view, 0, 1, w, 1))
+}
+
+func (p *exportPrompt) Size() (int, int) {
+	return p.MaxSize()
+}
+
+func (p *exportPrompt) MaxSize() (int, int) {
+	w, _ := p.file.MaxSize()
+	if width := runewidth.StringWidth(exportHint()); width > w {
+		w = width
+	}
+	return w, 4
+}
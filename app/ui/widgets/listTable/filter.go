@@ -0,0 +1,275 @@
+package listTable
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Filter is a filter typed by user
+type Filter struct {
+	Text  string `json:"text"`
+	Fuzzy bool   `json:"fuzzy,omitempty"`
+}
+
+func (f Filter) String() string {
+	if f.Fuzzy {
+		return "~" + f.Text
+	}
+	return "/" + f.Text
+}
+
+// query is a parsed filter. Filter consists of terms separated by spaces, and a row has to match all of them:
+//
+//	word          cells containing the word, case-insensitive unless the word has upper case letters
+//	/regex/       cells matching regular expression
+//	column:word   the same as above, but only the column is checked. Also column:/regex/
+//	column>3      column value compared as a number, duration or quantity. Also <, >=, <= and =
+//	!term         rows not matching the term
+//
+// Column names are case-insensitive, spaces could be omitted and unique prefix is enough: "restarts", "lastseen"
+type query struct {
+	terms []*term
+	// Errors of terms which couldn't be parsed. Such terms are ignored
+	err error
+}
+
+type term struct {
+	negate bool
+	// Index of the column to check, -1 means any column
+	cellId int
+	kind   columnKind
+	re     *regexp.Regexp
+	// Comparison operator and the value to compare with
+	op    string
+	value string
+}
+
+var comparisonOps = []string{">=", "<=", ">", "<", "="}
+
+var columnNameRe = regexp.MustCompile(`^[\p{L}][\p{L}\p{N} _.-]*$`)
+
+func parseQuery(filter string, columns []string, kindOf func(cellId int) columnKind) *query {
+	q := &query{}
+	var errs []string
+	for _, str := range splitTerms(filter) {
+		t, err := parseTerm(str, columns, kindOf)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if t != nil {
+			q.terms = append(q.terms, t)
+		}
+	}
+	if len(errs) > 0 {
+		q.err = fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return q
+}
+
+// splitTerms splits filter by spaces. Double quotes keep spaces within a term
+func splitTerms(filter string) []string {
+	var (
+		terms   []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, ch := range filter {
+		switch {
+		case ch == '"':
+			quoted = !quoted
+		case ch == ' ' && !quoted:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms
+}
+
+func parseTerm(str string, columns []string, kindOf func(cellId int) columnKind) (*term, error) {
+	t := &term{cellId: -1}
+	if strings.HasPrefix(str, "!") {
+		t.negate = true
+		str = str[1:]
+	}
+	if str == "" {
+		return nil, nil
+	}
+	if column, op, value, ok := splitColumn(str); ok {
+		cellId, err := findColumn(column, columns)
+		if err != nil {
+			return nil, err
+		}
+		t.cellId = cellId
+		t.kind = kindOf(cellId)
+		if op != ":" {
+			t.op = op
+			t.value = value
+			return t, nil
+		}
+		str = value
+	}
+	re, err := compilePattern(str)
+	if err != nil {
+		return nil, err
+	}
+	t.re = re
+	return t, nil
+}
+
+// splitColumn splits term like "status:Running" or "restarts>3" into column name, operator and value
+func splitColumn(str string) (string, string, string, bool) {
+	i := strings.IndexAny(str, ":<>=")
+	if i <= 0 || !columnNameRe.MatchString(str[:i]) {
+		return "", "", "", false
+	}
+	op := str[i : i+1]
+	for _, o := range comparisonOps {
+		if strings.HasPrefix(str[i:], o) {
+			op = o
+			break
+		}
+	}
+	return str[:i], op, str[i+len(op):], true
+}
+
+func normalizeColumn(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// findColumn looks the column up by its name or unique prefix
+func findColumn(name string, columns []string) (int, error) {
+	name = normalizeColumn(name)
+	found := -1
+	for i, column := range columns {
+		column = normalizeColumn(column)
+		if column == name {
+			return i, nil
+		}
+		if strings.HasPrefix(column, name) {
+			if found != -1 {
+				return -1, fmt.Errorf("ambiguous column %q", name)
+			}
+			found = i
+		}
+	}
+	if found == -1 {
+		return -1, fmt.Errorf("unknown column %q", name)
+	}
+	return found, nil
+}
+
+// compilePattern compiles "/regex/" as is and the rest as a literal string
+func compilePattern(str string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(str, "/") {
+		// Closing slash is optional, so the filter could be applied while regex is being typed
+		expr := strings.TrimSuffix(str[1:], "/")
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q", expr)
+		}
+		return re, nil
+	}
+	expr := regexp.QuoteMeta(str)
+	if strings.ToLower(str) == str {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr), nil
+}
+
+func (q *query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+func (q *query) Ranked() bool {
+	return false
+}
+
+func (q *query) Err() error {
+	return q.err
+}
+
+// Match checks whether cells match all terms of the query
+func (q *query) Match(cells []string) (int, bool) {
+	for _, t := range q.terms {
+		if t.match(cells) == t.negate {
+			return 0, false
+		}
+	}
+	return 0, true
+}
+
+func (t *term) match(cells []string) bool {
+	if t.cellId == -1 {
+		for _, cell := range cells {
+			if t.re.MatchString(cell) {
+				return true
+			}
+		}
+		return false
+	}
+	if t.cellId >= len(cells) {
+		return false
+	}
+	if t.re != nil {
+		return t.re.MatchString(cells[t.cellId])
+	}
+	return t.compare(cells[t.cellId])
+}
+
+func (t *term) compare(cell string) bool {
+	var c int
+	a, b := newSortKey(cell, t.kind), newSortKey(t.value, t.kind)
+	if a.numeric && b.numeric {
+		c = compareKeys(a, b)
+	} else {
+		// Values which aren't numbers are compared case-insensitively
+		c = strings.Compare(strings.ToLower(cell), strings.ToLower(t.value))
+	}
+	switch t.op {
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	}
+	return c == 0
+}
+
+func (q *query) Highlights(cellId int, value string) [][]int {
+	var ranges [][]int
+	for _, t := range q.terms {
+		if t.negate || (t.cellId != -1 && t.cellId != cellId) {
+			continue
+		}
+		if t.re == nil {
+			if t.compare(value) {
+				ranges = append(ranges, []int{0, len(value)})
+			}
+			continue
+		}
+		for _, r := range t.re.FindAllStringIndex(value, -1) {
+			if r[1] > r[0] {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,150 @@
+package listTable
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// matcher decides which rows pass the filter
+type matcher interface {
+	// Match reports whether cells match. Rows with higher rank go first when the matcher ranks rows
+	Match(cells []string) (int, bool)
+	// Highlights returns byte ranges of the cell value to highlight
+	Highlights(cellId int, value string) [][]int
+	// Ranked reports whether rows have to be ordered by rank
+	Ranked() bool
+	Empty() bool
+	Err() error
+}
+
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyBonusConsecutive = 8
+	fuzzyBonusBoundary    = 8
+	fuzzyBonusPrefix      = 4
+	fuzzyPenaltyGap       = 1
+)
+
+// fuzzyQuery matches cells containing characters of the pattern in the same order, like fzf does.
+// Matches with consecutive characters and characters at word boundaries get higher score
+type fuzzyQuery struct {
+	pattern       []rune
+	caseSensitive bool
+}
+
+func newFuzzyQuery(filter string) *fuzzyQuery {
+	pattern := strings.Replace(filter, " ", "", -1)
+	return &fuzzyQuery{
+		pattern:       []rune(pattern),
+		caseSensitive: strings.ToLower(pattern) != pattern,
+	}
+}
+
+func (q *fuzzyQuery) Empty() bool {
+	return len(q.pattern) == 0
+}
+
+func (q *fuzzyQuery) Ranked() bool {
+	return !q.Empty()
+}
+
+func (q *fuzzyQuery) Err() error {
+	return nil
+}
+
+// Match returns the best score among cells
+func (q *fuzzyQuery) Match(cells []string) (int, bool) {
+	best, matched := 0, false
+	for _, cell := range cells {
+		if score, positions := q.score(cell); positions != nil && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+func (q *fuzzyQuery) Highlights(_ int, value string) [][]int {
+	_, positions := q.score(value)
+	var ranges [][]int
+	for _, pos := range positions {
+		_, size := utf8.DecodeRuneInString(value[pos:])
+		if len(ranges) > 0 && ranges[len(ranges)-1][1] == pos {
+			ranges[len(ranges)-1][1] = pos + size
+			continue
+		}
+		ranges = append(ranges, []int{pos, pos + size})
+	}
+	return ranges
+}
+
+func (q *fuzzyQuery) equal(a, b rune) bool {
+	if q.caseSensitive {
+		return a == b
+	}
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// score finds pattern characters in the value and returns byte positions of the match.
+// Characters are found greedily and then the match is shrunk from the end, so "abc" in "a-abc" matches the tail
+func (q *fuzzyQuery) score(value string) (int, []int) {
+	if len(q.pattern) == 0 {
+		return 0, nil
+	}
+	runes := []rune(value)
+	// Forward pass finds where the match ends
+	p, end := 0, -1
+	for i, ch := range runes {
+		if q.equal(ch, q.pattern[p]) {
+			p++
+			if p == len(q.pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end == -1 {
+		return 0, nil
+	}
+	// Backward pass finds the shortest match ending there
+	indexes := make([]int, len(q.pattern))
+	p = len(q.pattern) - 1
+	for i := end; i >= 0 && p >= 0; i-- {
+		if q.equal(runes[i], q.pattern[p]) {
+			indexes[p] = i
+			p--
+		}
+	}
+	score := 0
+	if indexes[0] == 0 {
+		score += fuzzyBonusPrefix
+	}
+	for p, i := range indexes {
+		score += fuzzyScoreMatch
+		if i == 0 || isBoundary(runes[i-1]) {
+			score += fuzzyBonusBoundary
+		}
+		if p > 0 {
+			if gap := i - indexes[p-1] - 1; gap == 0 {
+				score += fuzzyBonusConsecutive
+			} else {
+				score -= gap * fuzzyPenaltyGap
+			}
+		}
+	}
+	// Convert rune indexes into byte positions
+	positions := make([]int, 0, len(indexes))
+	i, p := 0, 0
+	for byteIndex := range value {
+		if p < len(indexes) && indexes[p] == i {
+			positions = append(positions, byteIndex)
+			p++
+		}
+		i++
+	}
+	return score, positions
+}
+
+func isBoundary(ch rune) bool {
+	return !unicode.IsLetter(ch) && !unicode.IsDigit(ch)
+}
@@ -3,31 +3,55 @@ package listTable
 import (
 	"errors"
 	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
 	"github.com/mattn/go-runewidth"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"strings"
+	"sync"
 	"time"
 )
 
 type (
+	// ColumnFunc returns indexes of columns to show
+	ColumnFunc      func(columns []string, definitions []metav1.TableColumnDefinition) []int
 	RowFunc         func(row commander.Row) bool
 	RowKeyEventFunc func(row commander.Row, event *tcell.EventKey) bool
+	RowActionFunc   func(row commander.Row, a *actions.Action) bool
 	InitFunc        func()
+	FilterFunc      func(filter Filter)
 )
 
 var (
 	DefaultRowFunc         = func(row commander.Row) bool { return false }
 	DefaultRowKeyEventFunc = func(row commander.Row, event *tcell.EventKey) bool { return false }
+	DefaultRowActionFunc   = func(row commander.Row, a *actions.Action) bool { return false }
 	DefaultInit            = func() {}
+	DefaultFilterFunc      = func(filter Filter) {}
 )
 
+// MaxRedrawRate limits how many times per second ListTable applies incoming row operations.
+// Zero disables the limit
+var MaxRedrawRate = 30
+
+// DefaultColumnWidthKey is the key of MaxColumnWidths which applies to columns not listed there
+const DefaultColumnWidthKey = "default"
+
+// MaxColumnWidths limits widths of columns by name. Longer values are truncated with ellipsis. Zero disables the limit
+var MaxColumnWidths = map[string]int{
+	DefaultColumnWidthKey: 60,
+}
+
 const (
 	columnSeparator    = '|'
 	columnSeparatorLen = 1
+	// Minimum width of the age column
+	ageColumnWidth = 4
 )
 
 type TableFormat uint16
@@ -42,6 +66,8 @@ const (
 	NoActions
 	NoWatch
 	WithFilter
+	WithSort
+	WithMarks
 )
 
 func (tf TableFormat) Has(flag TableFormat) bool {
@@ -69,11 +95,25 @@ type ListTable struct {
 	views.WidgetWatchers
 	*focus.Focusable
 
-	view       views.View
+	view views.View
+	// All columns the rows have
+	allColumns  []string
+	definitions []metav1.TableColumnDefinition
+	// Columns to show and their indexes in allColumns
 	columns    []string
+	colIds     []int
+	columnFunc ColumnFunc
 	ageCol     int
-	rows       []commander.Row
-	rowIndex   map[string]int
+	// Column to sort rows by, -1 keeps the order rows come in
+	sortCol    int
+	sortBy     string
+	sortDesc   bool
+	sortKind   columnKind
+	keysStale  bool // Kind of the sort column was detected, so sort keys have to be computed again
+	sorted     bool // Rows are in sort order rather than the original one
+	seq        int  // Sequence number of the next inserted row
+	rows       *rowStore
+	widths     *widthTracker
 	selectedId string
 	format     TableFormat
 	// internal representation of table values
@@ -84,20 +124,44 @@ type ListTable struct {
 	topRow int
 	// Left cell to start rendering from (horizontal scrolling)
 	leftCell int
+	// Detects double clicks on rows
+	clicks mouse.DoubleClicks
+	// Action run by double click on a row
+	doubleClick *actions.Action
+	// Position to restore once its row is loaded
+	position *Position
+	loading  bool
 
 	onChange     RowFunc
 	onKeyEvent   RowKeyEventFunc
+	onAction     RowActionFunc
 	onInitStart  InitFunc
 	onInitFinish InitFunc
+	onFilter     FilterFunc
 
 	styler      commander.ListViewStyler
 	preloader   *preloader
 	rowProvider commander.RowProvider
 	updater     commander.ScreenUpdater
 	stopCh      chan struct{}
-
-	filter     string
-	filterMode bool
+	// Operations received from row provider which are yet to be applied on the UI event loop
+	pending []commander.Operation
+	// Positions of row modifications in the queue, so they could be merged
+	pendingModified map[string]int
+	pendingMu       sync.Mutex
+
+	filter      string
+	filterMode  bool
+	fuzzy       bool
+	query       matcher
+	filterScope func() string
+	// Filters kept per scope while the table is hidden, and the scope of the current filter
+	filters    map[string]Filter
+	shownScope string
+	// Ids of marked rows
+	marked     map[string]struct{}
+	caption    string
+	watchState commander.WatchState
 
 	stRow               commander.StyleComponent
 	stHeader            commander.StyleComponent
@@ -106,6 +170,12 @@ type ListTable struct {
 	stDisabled          commander.StyleComponent
 	stFilter            commander.StyleComponent
 	stFilterActive      commander.StyleComponent
+	stFilterMatch       commander.StyleComponent
+	stMarked            commander.StyleComponent
+	stCaption           commander.StyleComponent
+	stWatchConnected    commander.StyleComponent
+	stWatchReconnecting commander.StyleComponent
+	stWatchStale        commander.StyleComponent
 }
 
 func (lt *ListTable) GetComponents() []commander.StyleComponent {
@@ -116,24 +186,43 @@ func (lt *ListTable) GetComponents() []commander.StyleComponent {
 		lt.stSelectedUnfocused,
 		lt.stFilter,
 		lt.stFilterActive,
+		lt.stFilterMatch,
+		lt.stMarked,
+		lt.stCaption,
+		lt.stWatchConnected,
+		lt.stWatchReconnecting,
+		lt.stWatchStale,
 	}
 }
 
 func (lt *ListTable) Rows() []commander.Row {
-	return lt.rows
+	rows := make([]commander.Row, 0, lt.rows.Len())
+	lt.rows.Each(func(n *rowNode) {
+		rows = append(rows, n.row)
+	})
+	return rows
 }
 
 func NewListTable(prov commander.RowProvider, format TableFormat, updater commander.ScreenUpdater) *ListTable {
 	lt := &ListTable{
 		Focusable: focus.NewFocusable(),
 		format:    format,
-		rowIndex:  make(map[string]int),
+		rows:      newRowStore(),
+		widths:    newWidthTracker(0),
 		ageCol:    -1,
+		sortCol:   -1,
+		query:     &query{},
+		filters:   make(map[string]Filter),
+		marked:    make(map[string]struct{}),
+
+		pendingModified: make(map[string]int),
 
 		onKeyEvent:   DefaultRowKeyEventFunc,
+		onAction:     DefaultRowActionFunc,
 		onChange:     DefaultRowFunc,
 		onInitStart:  DefaultInit,
 		onInitFinish: DefaultInit,
+		onFilter:     DefaultFilterFunc,
 		styler:       DefaultStyler,
 		preloader:    NewPreloader(updater),
 		rowProvider:  prov,
@@ -146,133 +235,325 @@ func NewListTable(prov commander.RowProvider, format TableFormat, updater comman
 		stDisabled:          theme.NewComponent("disabled", theme.Default.Foreground(theme.ColorDisabledForeground)),
 		stFilter:            theme.NewComponent("filter", theme.Default.Background(theme.ColorSelectedUnfocusedBackground)),
 		stFilterActive:      theme.NewComponent("filter-active", theme.Default.Background(theme.ColorSelectedFocusedBackground)),
+		stFilterMatch:       theme.NewComponent("filter-match", theme.Default.Foreground(tcell.ColorYellow).Bold(true)),
+		stMarked:            theme.NewComponent("marked", theme.Default.Foreground(tcell.ColorFuchsia).Bold(true)),
+		stCaption:           theme.NewComponent("caption", theme.Default.Bold(true)),
+		stWatchConnected:    theme.NewComponent("watch-connected", theme.Default.Foreground(tcell.ColorDarkGreen)),
+		stWatchReconnecting: theme.NewComponent("watch-reconnecting", theme.Default.Foreground(tcell.ColorYellow)),
+		stWatchStale:        theme.NewComponent("watch-stale", theme.Default.Foreground(tcell.ColorDarkRed)),
 	}
 	lt.Render()
 	return lt
 }
 
 func (lt *ListTable) OnShow() {
-	lt.stopCh = make(chan struct{})
-	go lt.watch()
-	lt.resetFilter()
+	stopCh := make(chan struct{})
+	lt.stopCh = stopCh
+	go lt.watch(stopCh)
+	lt.restoreFilter()
 	lt.Focusable.OnShow()
 }
 
 func (lt *ListTable) OnHide() {
-	lt.Focusable.OnShow()
+	lt.keepFilter()
+	lt.Focusable.OnHide()
 	close(lt.stopCh)
 }
 
-func (lt *ListTable) watch() {
+// watch receives row operations and passes them to the UI event loop. Widget state is only modified
+// on the UI event loop, so it never races with HandleEvent and Draw. Operations are applied at most
+// MaxRedrawRate times per second, so bursts of events are coalesced into a single frame
+func (lt *ListTable) watch(stopCh chan struct{}) {
 	ticker := time.NewTicker(time.Second * 5)
 	defer ticker.Stop()
+	var (
+		frame     <-chan time.Time
+		lastFrame time.Time
+	)
 	for {
 		select {
-		case <-lt.stopCh:
+		case <-stopCh:
 			return
 		case ops, ok := <-lt.rowProvider:
 			if !ok {
+				if frame != nil {
+					lt.updater.PostFunc(lt.applyPending)
+				}
 				return
 			}
-			changed := false
-			for _, operation := range ops {
-				switch op := operation.(type) {
-				case *commander.OpClear:
-					lt.rows = []commander.Row{}
-					lt.rowIndex = make(map[string]int)
-					lt.columns = []string{}
-					changed = true
-				case *commander.OpSetColumns:
-					// Compare columns content
-					if strings.Join(lt.columns, "|") != strings.Join(op.Columns, "|") {
-						lt.columns = op.Columns
-						changed = true
-					}
-					lt.setAgeCol()
-				case *commander.OpAdded:
-					index, ok := lt.rowIndex[op.Row.Id()]
-					if !ok {
-						if op.Index == nil {
-							if op.SortById {
-								index = lt.getRowIndex(op.Row)
-							} else {
-								index = len(lt.rows)
-							}
-						} else {
-							index = *op.Index
-						}
-						for key, val := range lt.rowIndex {
-							if val >= index {
-								lt.rowIndex[key] = val + 1
-							}
-						}
-						lt.rowIndex[op.Row.Id()] = index
-						lt.rows = append(lt.rows[:index], append([]commander.Row{op.Row}, lt.rows[index:]...)...)
-						changed = true
-					} else {
-						// If row already exists
-						lt.rows[index] = op.Row
-						// TODO: move row if new index provided?
-					}
-				case *commander.OpDeleted:
-					index, ok := lt.rowIndex[op.RowId]
-					if ok {
-						lt.rows = append(lt.rows[:index], lt.rows[index+1:]...)
-						delete(lt.rowIndex, op.RowId)
-						for _, row := range lt.rows[index:] {
-							lt.rowIndex[row.Id()]--
-						}
-						changed = true
-					}
-				case *commander.OpModified:
-					index, ok := lt.rowIndex[op.Row.Id()]
-					if ok {
-						// Compare cells content
-						if strings.Join(lt.rows[index].Cells(), "|") != strings.Join(op.Row.Cells(), "|") {
-							lt.rows[index] = op.Row
-							changed = true
-						}
-						if lt.rows[index].Enabled() != op.Row.Enabled() {
-							lt.rows[index] = op.Row
-							changed = true
-						}
-					} else {
-						lt.rows = append(lt.rows, op.Row)
-						changed = true
-					}
-				case *commander.OpInitStart:
-					lt.preloader.Start()
-					lt.onInitStart()
-				case *commander.OpInitFinished:
-					lt.preloader.Stop()
-					lt.onInitFinish()
-				}
-			}
-			if changed {
-				lt.Render()
-				lt.reindexSelection()
-				if lt.updater != nil {
-					lt.updater.Resize()
-					lt.updater.UpdateScreen()
-				}
+			lt.enqueue(ops)
+			if frame == nil {
+				frame = time.After(frameInterval() - time.Since(lastFrame))
 			}
+		case <-frame:
+			frame = nil
+			lastFrame = time.Now()
+			lt.updater.PostFunc(lt.applyPending)
 		case <-ticker.C:
 			// Periodically update list to ensure that age is somewhat relevant
-			if lt.ageCol != -1 {
-				lt.Render()
-				if lt.updater != nil {
-					lt.updater.Resize()
-					lt.updater.UpdateScreen()
+			lt.updater.PostFunc(lt.refreshAge)
+		}
+	}
+}
+
+// frameInterval returns minimal interval between applying row operations
+func frameInterval() time.Duration {
+	if MaxRedrawRate <= 0 {
+		return 0
+	}
+	return time.Second / time.Duration(MaxRedrawRate)
+}
+
+// enqueue queues operations to be applied on the next frame. Modifications of the same row are merged,
+// unless the row was added or deleted in between
+func (lt *ListTable) enqueue(ops []commander.Operation) {
+	lt.pendingMu.Lock()
+	defer lt.pendingMu.Unlock()
+	for _, operation := range ops {
+		switch op := operation.(type) {
+		case *commander.OpModified:
+			if index, ok := lt.pendingModified[op.Row.Id()]; ok {
+				lt.pending[index] = op
+				continue
+			}
+			lt.pendingModified[op.Row.Id()] = len(lt.pending)
+		case *commander.OpAdded:
+			delete(lt.pendingModified, op.Row.Id())
+		case *commander.OpDeleted:
+			delete(lt.pendingModified, op.RowId)
+		case *commander.OpClear, *commander.OpSetColumns:
+			lt.pendingModified = make(map[string]int)
+		}
+		lt.pending = append(lt.pending, operation)
+	}
+}
+
+// Apply applies operations right away, after the ones received from the row provider.
+// Must be called on the UI event loop
+func (lt *ListTable) Apply(ops []commander.Operation) {
+	lt.enqueue(ops)
+	lt.applyPending()
+}
+
+// applyPending applies queued operations. Must be called on the UI event loop
+func (lt *ListTable) applyPending() {
+	if lt.flush() {
+		lt.updater.Resize()
+	}
+}
+
+// flush applies queued operations and returns whether the table has changed
+func (lt *ListTable) flush() bool {
+	lt.pendingMu.Lock()
+	pending := lt.pending
+	lt.pending = nil
+	lt.pendingModified = make(map[string]int)
+	lt.pendingMu.Unlock()
+	if len(pending) == 0 {
+		return false
+	}
+	changed := lt.apply(pending)
+	if changed {
+		lt.Render()
+		lt.reindexSelection()
+	}
+	lt.applyPosition()
+	return changed
+}
+
+func (lt *ListTable) refreshAge() {
+	if lt.ageCol != -1 {
+		lt.Render()
+	}
+}
+
+// apply applies operations to the rows and returns whether the table has to be rendered again
+func (lt *ListTable) apply(ops []commander.Operation) bool {
+	changed := false
+	for _, operation := range ops {
+		switch op := operation.(type) {
+		case *commander.OpClear:
+			lt.ClearMarks()
+			lt.rows = newRowStore()
+			lt.widths = newWidthTracker(0)
+			lt.allColumns = []string{}
+			lt.definitions = nil
+			lt.columns = []string{}
+			lt.colIds = nil
+			lt.ageCol = -1
+			changed = true
+		case *commander.OpSetColumns:
+			// Compare columns content
+			if strings.Join(lt.allColumns, "|") != strings.Join(op.Columns, "|") {
+				lt.allColumns = op.Columns
+				lt.definitions = op.Definitions
+				lt.projectColumns()
+				changed = true
+			}
+		case *commander.OpAdded:
+			if n := lt.rows.Get(op.Row.Id()); n != nil {
+				// If row already exists
+				lt.updateRow(n, op.Row)
+				// TODO: move row if new index provided?
+			} else {
+				lt.insertRow(op.Row, origin{index: op.Index, byId: op.SortById})
+			}
+			changed = true
+		case *commander.OpDeleted:
+			if n := lt.rows.Delete(op.RowId); n != nil {
+				delete(lt.marked, op.RowId)
+				if n.matched {
+					lt.widths.Remove(n.widths)
 				}
+				changed = true
+			}
+		case *commander.OpModified:
+			if n := lt.rows.Get(op.Row.Id()); n != nil {
+				// Compare cells content
+				if strings.Join(n.row.Cells(), "|") != strings.Join(op.Row.Cells(), "|") || n.row.Enabled() != op.Row.Enabled() {
+					lt.updateRow(n, op.Row)
+					changed = true
+				}
+			} else {
+				lt.insertRow(op.Row, origin{})
+				changed = true
+			}
+		case *commander.OpInitStart:
+			lt.loading = true
+			lt.watchState = commander.WatchNone
+			lt.preloader.Start()
+			lt.onInitStart()
+		case *commander.OpInitProgress:
+			lt.preloader.SetProgress(op.Loaded, op.Total)
+		case *commander.OpSetWatchState:
+			if lt.watchState != op.State {
+				lt.watchState = op.State
+				changed = true
 			}
+		case *commander.OpInitFinished:
+			lt.loading = false
+			lt.preloader.Stop()
+			lt.onInitFinish()
+		}
+	}
+	if lt.keysStale {
+		lt.rekey()
+		if lt.ordered() {
+			lt.resort()
+		}
+	}
+	return changed
+}
+
+func (lt *ListTable) insertRow(row commander.Row, at origin) {
+	n := &rowNode{row: row, seq: lt.seq}
+	lt.seq++
+	n.values, n.widths = lt.renderRow(row)
+	n.key = lt.sortKey(n)
+	n.rank, n.matched = lt.matchFilter(row)
+	if lt.ordered() {
+		// Position is defined by sort order, requested one is used once the original order is restored
+		n.origin = &at
+		lt.rows.Insert(lt.sortedIndex(n), n)
+	} else {
+		lt.rows.Insert(lt.originIndex(row.Id(), at), n)
+	}
+	if n.matched {
+		lt.widths.Add(n.widths)
+	}
+}
+
+// originIndex returns position of the row in the original order
+func (lt *ListTable) originIndex(id string, at origin) int {
+	switch {
+	case at.index != nil:
+		return *at.index
+	case at.byId:
+		return lt.rows.SortedIndex(id)
+	}
+	return lt.rows.Len()
+}
+
+func (lt *ListTable) updateRow(n *rowNode, row commander.Row) {
+	if n.matched {
+		lt.widths.Remove(n.widths)
+	}
+	n.row = row
+	n.values, n.widths = lt.renderRow(row)
+	n.key = lt.sortKey(n)
+	rank, matched := lt.matchFilter(row)
+	if lt.ordered() {
+		// Row is moved to keep sort order
+		lt.rows.Delete(row.Id())
+		n.rank, n.matched = rank, matched
+		lt.rows.Insert(lt.sortedIndex(n), n)
+	} else {
+		lt.rows.SetMatched(n, matched)
+	}
+	if n.matched {
+		lt.widths.Add(n.widths)
+	}
+}
+
+// projectColumns picks columns to show
+func (lt *ListTable) projectColumns() {
+	lt.columns = nil
+	lt.colIds = nil
+	if lt.columnFunc != nil {
+		lt.colIds = lt.columnFunc(lt.allColumns, lt.definitions)
+	} else {
+		for i := range lt.allColumns {
+			lt.colIds = append(lt.colIds, i)
+		}
+	}
+	for _, colId := range lt.colIds {
+		lt.columns = append(lt.columns, lt.allColumns[colId])
+	}
+	lt.setAgeCol()
+	lt.setSortCol()
+	lt.remeasure()
+	if lt.ordered() || lt.sorted {
+		lt.resort()
+	}
+}
+
+// SetColumnFunc sets function which picks columns to show
+func (lt *ListTable) SetColumnFunc(columnFunc ColumnFunc) {
+	lt.columnFunc = columnFunc
+	lt.projectColumns()
+	lt.Render()
+	lt.reindexSelection()
+}
+
+// AllColumns returns all columns the rows have, including hidden ones
+func (lt *ListTable) AllColumns() ([]string, []metav1.TableColumnDefinition) {
+	return lt.allColumns, lt.definitions
+}
+
+// remeasure renders values of every row again. It is needed only when columns or filter change
+func (lt *ListTable) remeasure() {
+	if lt.fuzzy {
+		lt.query = newFuzzyQuery(lt.filter)
+	} else {
+		lt.query = parseQuery(lt.filter, lt.allColumns, lt.cellKind)
+	}
+	lt.widths = newWidthTracker(len(lt.columns))
+	lt.rows.Each(func(n *rowNode) {
+		n.values, n.widths = lt.renderRow(n.row)
+		n.key = lt.sortKey(n)
+		n.rank, n.matched = lt.matchFilter(n.row)
+		if n.matched {
+			lt.widths.Add(n.widths)
 		}
+	})
+	lt.rows.Recount()
+	if lt.keysStale {
+		lt.rekey()
 	}
 }
 
 type table struct {
-	rows    []commander.Row
 	headers []string
-	values  [][]string
 
 	columnDataWidths []int
 	dataWidth        int
@@ -288,11 +569,8 @@ func (lt *ListTable) SelectedRowId() string {
 }
 
 func (lt *ListTable) SelectedRow() commander.Row {
-	if len(lt.table.rows) == 0 {
-		return nil
-	}
-	if lt.selectedRowIndex < len(lt.table.rows) {
-		return lt.table.rows[lt.selectedRowIndex]
+	if n := lt.rows.MatchedAt(lt.selectedRowIndex); n != nil {
+		return n.row
 	}
 	return nil
 }
@@ -303,18 +581,20 @@ func (lt *ListTable) SetStyler(styler commander.ListViewStyler) {
 }
 
 func (lt *ListTable) rowStyle(row commander.Row) commander.Style {
+	style := lt.stRow.Style()
 	if row.Id() == lt.SelectedRowId() {
 		if lt.IsFocused() {
-			return lt.stSelectedFocused.Style()
+			style = lt.stSelectedFocused.Style()
 		} else {
-			return lt.stSelectedUnfocused.Style()
+			style = lt.stSelectedUnfocused.Style()
 		}
+	} else if row != nil && !row.Enabled() {
+		style = lt.stDisabled.Style()
 	}
-	if row != nil && !row.Enabled() {
-		return lt.stDisabled.Style()
+	if lt.isMarked(row) {
+		style = lt.markedStyle(style)
 	}
-	return lt.stRow.Style()
-
+	return style
 }
 
 func (lt *ListTable) BindOnChange(rowFunc RowFunc) {
@@ -329,9 +609,69 @@ func (lt *ListTable) BindOnChange(rowFunc RowFunc) {
 
 func (lt *ListTable) resetFilter() {
 	lt.filterMode = false
-	lt.filter = ""
+	lt.setFilter("")
+}
+
+func (lt *ListTable) setFilter(filter string) {
+	lt.SetFilter(Filter{Text: filter, Fuzzy: lt.fuzzy})
+}
+
+// SetFilter filters rows. Fuzzy filter ranks rows by match quality
+func (lt *ListTable) SetFilter(filter Filter) {
+	lt.filter = filter.Text
+	lt.fuzzy = filter.Fuzzy
+	lt.remeasure()
+	if lt.ordered() || lt.sorted {
+		lt.resort()
+	}
 	lt.Render()
 	lt.reindexSelection()
+	lt.onFilter(filter)
+}
+
+// EditFilter sets the filter and starts editing it, as if user pressed the filter key
+func (lt *ListTable) EditFilter(filter Filter) {
+	lt.SetFilter(filter)
+	lt.filterMode = true
+}
+
+// Filter returns the current filter
+func (lt *ListTable) Filter() Filter {
+	return Filter{Text: lt.filter, Fuzzy: lt.fuzzy}
+}
+
+// SetFilterScope sets function which returns the scope of filters, for example the current namespace.
+// Table keeps a filter for every scope, so it is restored when the table is shown within the same scope again
+func (lt *ListTable) SetFilterScope(scope func() string) {
+	lt.filterScope = scope
+}
+
+func (lt *ListTable) scope() string {
+	if lt.filterScope == nil {
+		return ""
+	}
+	return lt.filterScope()
+}
+
+// restoreFilter applies the filter which was set the last time the table was shown within the current scope
+func (lt *ListTable) restoreFilter() {
+	lt.filterMode = false
+	lt.shownScope = lt.scope()
+	lt.SetFilter(lt.filters[lt.shownScope])
+}
+
+// keepFilter remembers the current filter for the scope it was set in
+func (lt *ListTable) keepFilter() {
+	if lt.filter == "" {
+		delete(lt.filters, lt.shownScope)
+		return
+	}
+	lt.filters[lt.shownScope] = lt.Filter()
+}
+
+// SetCaption sets a line of text to be shown above table headers
+func (lt *ListTable) SetCaption(caption string) {
+	lt.caption = caption
 }
 
 func (lt *ListTable) BindOnInitFinish(initFunc InitFunc) {
@@ -342,6 +682,15 @@ func (lt *ListTable) BindOnInitFinish(initFunc InitFunc) {
 	}
 }
 
+// BindOnFilter sets function which is called after the filter changes
+func (lt *ListTable) BindOnFilter(filterFunc FilterFunc) {
+	oldFunc := lt.onFilter
+	lt.onFilter = func(filter Filter) {
+		filterFunc(filter)
+		oldFunc(filter)
+	}
+}
+
 func (lt *ListTable) BindOnInitStart(initFunc InitFunc) {
 	oldFunc := lt.onInitStart
 	lt.onInitStart = func() {
@@ -351,8 +700,8 @@ func (lt *ListTable) BindOnInitStart(initFunc InitFunc) {
 }
 
 func (lt *ListTable) RowById(id string) commander.Row {
-	if index, ok := lt.rowIndex[id]; ok {
-		return lt.rows[index]
+	if n := lt.rows.Get(id); n != nil {
+		return n.row
 	}
 	return nil
 }
@@ -367,6 +716,27 @@ func (lt *ListTable) BindOnKeyPress(rowKeyEventFunc RowKeyEventFunc) {
 	}
 }
 
+// SetDoubleClickAction sets the action which double click on a row runs
+func (lt *ListTable) SetDoubleClickAction(a *actions.Action) {
+	lt.doubleClick = a
+}
+
+// BindOnAction binds actions of the scopes to the selected row. They are run by their keys, as well as by HandleAction
+func (lt *ListTable) BindOnAction(rowActionFunc RowActionFunc, scopes ...actions.Scope) {
+	oldFunc := lt.onAction
+	lt.onAction = func(row commander.Row, a *actions.Action) bool {
+		if rowActionFunc(row, a) {
+			return true
+		}
+		return oldFunc(row, a)
+	}
+	lt.BindOnKeyPress(func(row commander.Row, event *tcell.EventKey) bool {
+		return actions.HandleKey(event, func(a *actions.Action) bool {
+			return rowActionFunc(row, a)
+		}, scopes...)
+	})
+}
+
 func (lt *ListTable) columnSeparatorsWidth() int {
 	return (len(lt.columns) - 1) * columnSeparatorLen
 }
@@ -381,6 +751,9 @@ func (lt *ListTable) tableHeight() int {
 	if lt.format.Has(WithHeaders) {
 		height -= 1
 	}
+	if lt.caption != "" {
+		height -= 1
+	}
 	if lt.filterMode || lt.filter != "" {
 		height -= 1
 	}
@@ -394,64 +767,138 @@ func (lt *ListTable) MaxSize() (w int, h int) {
 	if lt.format.Has(WithHeaders) {
 		h++
 	}
+	if lt.caption != "" {
+		h++
+	}
 	return w, h
 }
 
-func (lt *ListTable) matchFilter(row commander.Row) bool {
-	if lt.filter == "" {
-		return true
+// matchFilter reports whether the row passes the filter and its rank
+func (lt *ListTable) matchFilter(row commander.Row) (int, bool) {
+	if lt.query.Empty() {
+		return 0, true
 	}
-	for _, cell := range row.Cells() {
-		if strings.Contains(cell, lt.filter) {
-			return true
+	return lt.query.Match(lt.filterCells(row))
+}
+
+// Matches reports whether the row passes the current filter
+func (lt *ListTable) Matches(row commander.Row) bool {
+	_, matched := lt.matchFilter(row)
+	return matched
+}
+
+// filterCells returns cells to check against the filter, with up to date age and keywords of the row
+func (lt *ListTable) filterCells(row commander.Row) []string {
+	cells := row.Cells()
+	keywordsRow, hasKeywords := row.(commander.RowWithKeywords)
+	ageRow, hasAge := row.(commander.RowWithAge)
+	if !hasKeywords && (!hasAge || lt.ageCol == -1) {
+		return cells
+	}
+	fresh := make([]string, len(cells))
+	copy(fresh, cells)
+	if hasAge && lt.ageCol != -1 {
+		if ageCellId := lt.colIds[lt.ageCol]; ageCellId < len(cells) {
+			fresh[ageCellId] = lt.renderAge(ageRow.Age())
 		}
 	}
-	return false
+	if hasKeywords {
+		fresh = append(fresh, keywordsRow.Keywords()...)
+	}
+	return fresh
+}
+
+func (lt *ListTable) cellKind(cellId int) columnKind {
+	var definition *metav1.TableColumnDefinition
+	if cellId < len(lt.definitions) {
+		definition = &lt.definitions[cellId]
+	}
+	return columnKindOf(lt.allColumns[cellId], definition)
+}
+
+// highlights returns ranges of filter matches in every visible cell
+func (lt *ListTable) highlights(values []string) [][][]int {
+	if lt.query.Empty() {
+		return nil
+	}
+	highlights := make([][][]int, len(values))
+	for colId, value := range values {
+		if colId < len(lt.colIds) {
+			highlights[colId] = lt.query.Highlights(lt.colIds[colId], value)
+		}
+	}
+	return highlights
+}
+
+// renderRow renders values of cells and measures their widths
+func (lt *ListTable) renderRow(row commander.Row) ([]string, []int) {
+	cells := row.Cells()
+	ageRow, _ := row.(commander.RowWithAge)
+	values := make([]string, len(lt.columns))
+	widths := make([]int, len(lt.columns))
+	for colId, cellId := range lt.colIds {
+		var (
+			err   error
+			value string
+		)
+		if colId == lt.ageCol && ageRow != nil {
+			value = lt.renderAge(ageRow.Age())
+		} else if cellId > len(cells)-1 {
+			err = errors.New("no val")
+		} else {
+			value = cells[cellId]
+		}
+		if err != nil {
+			value = "err: " + err.Error()
+		}
+		values[colId] = value
+		widths[colId] = runewidth.StringWidth(value)
+	}
+	return values, widths
+}
+
+// rowValues returns values to draw. Age is rendered right before drawing to keep it relevant
+func (lt *ListTable) rowValues(n *rowNode) []string {
+	ageRow, ok := n.row.(commander.RowWithAge)
+	if lt.ageCol == -1 || !ok || lt.ageCol >= len(n.values) {
+		return n.values
+	}
+	values := make([]string, len(n.values))
+	copy(values, n.values)
+	values[lt.ageCol] = lt.renderAge(ageRow.Age())
+	return values
 }
 
 func (lt *ListTable) renderTable() table {
 	t := table{}
-	t.dataHeight = len(lt.rows)
-	t.columnDataWidths = []int{}
-	if lt.format.Has(WithHeaders) {
-		for _, col := range lt.columns {
+	t.dataHeight = lt.rows.Matched()
+	t.columnDataWidths = make([]int, len(lt.columns))
+	for colId, col := range lt.columns {
+		if lt.format.Has(WithHeaders) {
+			if colId == lt.sortCol {
+				if lt.sortDesc {
+					col += " ▼"
+				} else {
+					col += " ▲"
+				}
+			}
 			t.headers = append(t.headers, col)
-			t.columnDataWidths = append(t.columnDataWidths, runewidth.StringWidth(col))
+			t.columnDataWidths[colId] = runewidth.StringWidth(col)
 		}
-		t.dataHeight += 1
-	} else {
-		t.columnDataWidths = make([]int, len(lt.columns))
-	}
-	for _, row := range lt.rows {
-		if !lt.matchFilter(row) {
-			continue
+		width := lt.widths.Max(colId)
+		if max := maxColumnWidth(lt.columns[colId]); max > 0 && width > max {
+			width = max
 		}
-		cells := row.Cells()
-		ageRow, _ := row.(commander.RowWithAge)
-		var mRow []string
-		for colId := range lt.columns {
-			var (
-				err   error
-				value string
-			)
-			if colId == lt.ageCol && ageRow != nil {
-				value = lt.renderAge(ageRow.Age())
-			} else if colId > len(cells)-1 {
-				err = errors.New("no val")
-			} else {
-				value = cells[colId]
-			}
-			if err != nil {
-				value = "err: " + err.Error()
-			}
-			width := runewidth.StringWidth(value)
-			if width > t.columnDataWidths[colId] {
-				t.columnDataWidths[colId] = width
-			}
-			mRow = append(mRow, value)
+		if width > t.columnDataWidths[colId] {
+			t.columnDataWidths[colId] = width
+		}
+		// Age is measured when row arrives, but it grows over time
+		if colId == lt.ageCol && t.columnDataWidths[colId] < ageColumnWidth {
+			t.columnDataWidths[colId] = ageColumnWidth
 		}
-		t.values = append(t.values, mRow)
-		t.rows = append(t.rows, row)
+	}
+	if lt.format.Has(WithHeaders) {
+		t.dataHeight += 1
 	}
 	t.dataWidth = 0
 	for _, width := range t.columnDataWidths {
@@ -490,37 +937,93 @@ func (lt *ListTable) getColumnSizes() []int {
 }
 
 func (lt *ListTable) Draw() {
+	// Operations could arrive while UI event loop was busy, so they are applied right before drawing
+	if lt.flush() {
+		lt.updater.PostFunc(lt.updater.Resize)
+	}
 	style := lt.defaultStyle()
 	lt.view.Fill(' ', style)
 	index := 0
+	if lt.caption != "" {
+		lt.drawLine(index, lt.caption, lt.stCaption.Style())
+		index++
+	}
 	if lt.filterMode || lt.filter != "" {
 		lt.drawFilter(index)
 		index++
 	}
 	sizes := lt.getColumnSizes()
 	if lt.format.Has(WithHeaders) {
-		lt.drawRow(index, lt.table.headers, sizes, lt.stHeader.Style())
+		lt.drawRow(index, lt.table.headers, sizes, lt.stHeader.Style(), nil)
 		index++
 	}
-	for rowId := lt.topRow; rowId < lt.topRow+lt.tableHeight() && rowId < len(lt.table.rows); rowId++ {
-		lt.drawRow(index, lt.table.values[rowId], sizes, lt.rowStyle(lt.table.rows[rowId]))
+	// Only rows within the viewport are drawn
+	for rowId := lt.topRow; rowId < lt.topRow+lt.tableHeight(); rowId++ {
+		n := lt.rows.MatchedAt(rowId)
+		if n == nil {
+			break
+		}
+		values := lt.rowValues(n)
+		lt.drawRow(index, values, sizes, lt.rowStyle(n.row), lt.highlights(values))
 		index++
 	}
+	lt.drawMarks(lt.drawWatchState())
 	lt.preloader.Draw()
 }
 
 func (lt *ListTable) drawFilter(y int) {
-	str := "/" + lt.filter
-	x := 0
 	var st commander.Style
 	if lt.filterMode {
 		st = lt.stFilterActive.Style()
 	} else {
 		st = lt.stFilter.Style()
 	}
-	for _, ch := range str {
+	lt.drawLine(y, lt.Filter().String(), st)
+	// Errors are shown in place of the counter, so mistyped terms are easy to notice
+	status := fmt.Sprintf("%d of %d rows", lt.rows.Matched(), lt.rows.Len())
+	if err := lt.query.Err(); err != nil {
+		status = err.Error()
+	}
+	x := lt.viewWidth() - runewidth.StringWidth(status)
+	if min := runewidth.StringWidth(lt.filter) + 2; x < min {
+		x = min
+	}
+	for _, ch := range status {
 		lt.view.SetContent(x, y, ch, nil, st)
-		x++
+		x += runewidth.RuneWidth(ch)
+	}
+}
+
+// drawWatchState draws watch connection indicator in the top right corner and returns where it starts
+func (lt *ListTable) drawWatchState() int {
+	var (
+		str   string
+		style commander.Style
+	)
+	switch lt.watchState {
+	case commander.WatchConnected:
+		str, style = "● live", lt.stWatchConnected.Style()
+	case commander.WatchReconnecting:
+		str, style = "◌ reconnecting", lt.stWatchReconnecting.Style()
+	case commander.WatchStale:
+		str, style = "✕ stale", lt.stWatchStale.Style()
+	default:
+		return lt.viewWidth()
+	}
+	start := lt.viewWidth() - runewidth.StringWidth(str)
+	x := start
+	for _, ch := range str {
+		lt.view.SetContent(x, 0, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+	return start - 1
+}
+
+func (lt *ListTable) drawLine(y int, str string, style tcell.Style) {
+	x := 0
+	for _, ch := range str {
+		lt.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
 	}
 }
 
@@ -528,30 +1031,33 @@ func (lt *ListTable) defaultStyle() tcell.Style {
 	return tcell.StyleDefault.Background(tcell.ColorTeal)
 }
 
-func (lt *ListTable) drawRow(y int, row []string, sizes []int, style tcell.Style) {
-	rowString := ""
+// drawRow draws cell values. Highlights are byte ranges of every value to draw with filter match style
+func (lt *ListTable) drawRow(y int, row []string, sizes []int, style tcell.Style, highlights [][][]int) {
+	matchStyle := lt.matchStyle(style)
+	w := lt.newCellWriter(y)
+	x := 0
 	for i, val := range row {
-		rowString += val
-		if len(val) < sizes[i] {
-			rowString += strings.Repeat(" ", sizes[i]-len(val))
+		var ranges [][]int
+		if highlights != nil {
+			ranges = highlights[i]
 		}
+		x = w.cell(x, val, sizes[i], style, matchStyle, ranges)
 		if i < len(row)-1 {
-			rowString += string(columnSeparator)
+			x = w.put(x, columnSeparator, style)
 		}
-	}
-	rowString = rowString[lt.leftCell:]
-	x := 0
-	padding := 0
-	for _, ch := range rowString {
-		if runewidth.IsAmbiguousWidth(ch) {
-			padding += 2
+		if x-lt.leftCell >= w.width {
+			break
 		}
-		lt.view.SetContent(x, y, ch, nil, style)
-		x++
-	}
-	for i := 0; i < padding; i++ {
-		lt.view.SetContent(x+i, y, ' ', nil, style)
 	}
+	w.flush()
+}
+
+// matchStyle applies filter match style on top of the row style, so the row background is kept
+func (lt *ListTable) matchStyle(style tcell.Style) tcell.Style {
+	fg, _, attrs := lt.stFilterMatch.Style().Decompose()
+	return style.Foreground(fg).
+		Bold(attrs&tcell.AttrBold != 0).
+		Underline(attrs&tcell.AttrUnderline != 0)
 }
 
 func (lt *ListTable) Render() {
@@ -563,74 +1069,83 @@ func (lt *ListTable) Resize() {
 }
 
 func (lt *ListTable) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventMouse); ok {
+		return lt.handleMouse(e)
+	}
 	return KeySwitch(ev, func(ev *tcell.EventKey) bool {
-		if ev.Modifiers() != tcell.ModNone {
-			return false
-		}
-		switch ev.Key() {
-		case tcell.KeyDown:
-			lt.Next()
-			return true
-		case tcell.KeyUp:
-			lt.Prev()
-			return true
-		case tcell.KeyPgDn:
-			lt.NextPage()
-			return true
-		case tcell.KeyPgUp:
-			lt.PrevPage()
-			return true
-		case tcell.KeyHome:
-			lt.Home()
-			return true
-		case tcell.KeyEnd:
-			lt.End()
-			return true
-		case tcell.KeyRight:
-			lt.Right()
-			return true
-		case tcell.KeyLeft:
-			lt.Left()
+		if lt.filterMode && lt.handleFilterKey(ev) {
 			return true
 		}
-		if lt.format.Has(WithFilter) {
-			if (lt.filterMode || lt.filter != "") && ev.Key() == tcell.KeyEsc {
-				lt.resetFilter()
-				return true
-			}
-			if lt.filterMode {
-				switch ev.Key() {
-				case tcell.KeyBackspace2:
-					if len(lt.filter) > 0 {
-						lt.filter = lt.filter[:len(lt.filter)-1]
-						lt.Render()
-						lt.reindexSelection()
-					}
-					return true
-				case tcell.KeyEnter:
-					lt.filterMode = false
-					lt.Render()
-					lt.reindexSelection()
-					return true
-				}
-				if ev.Rune() != 0 {
-					lt.filter += string(ev.Rune())
-					lt.Render()
-					lt.reindexSelection()
-					return true
-				}
-			} else {
-				if ev.Rune() == '/' {
-					lt.filterMode = true
-					return true
-				}
-			}
-
+		if actions.HandleKey(ev, lt.handleAction, actions.List, actions.Marks) {
+			return true
 		}
 		return lt.onKeyEvent(lt.SelectedRow(), ev)
 	})
 }
 
+// HandleAction runs the action as if its key was pressed
+func (lt *ListTable) HandleAction(a *actions.Action) bool {
+	return lt.handleAction(a) || lt.onAction(lt.SelectedRow(), a)
+}
+
+// handleAction runs list and marks actions
+func (lt *ListTable) handleAction(a *actions.Action) bool {
+	filter := lt.format.Has(WithFilter)
+	switch {
+	case a == ActDown:
+		lt.Next()
+	case a == ActUp:
+		lt.Prev()
+	case a == ActPageDown:
+		lt.NextPage()
+	case a == ActPageUp:
+		lt.PrevPage()
+	case a == ActHome:
+		lt.Home()
+	case a == ActEnd:
+		lt.End()
+	case a == ActRight:
+		lt.Right()
+	case a == ActLeft:
+		lt.Left()
+	case lt.format.Has(WithSort) && a == actSort:
+		lt.CycleSort()
+	case filter && (lt.filterMode || lt.filter != "") && a == ActClearFilter:
+		lt.resetFilter()
+	case filter && !lt.filterMode && a == ActFilter:
+		lt.filterMode = true
+	case lt.format.Has(WithMarks) && lt.handleMarkAction(a):
+	default:
+		return false
+	}
+	return true
+}
+
+// handleFilterKey edits the filter being typed. Keys which don't edit text are left for actions
+func (lt *ListTable) handleFilterKey(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyBackspace2:
+		if len(lt.filter) > 0 {
+			lt.setFilter(lt.filter[:len(lt.filter)-1])
+		}
+		return true
+	case tcell.KeyEnter:
+		lt.filterMode = false
+		lt.Render()
+		lt.reindexSelection()
+		return true
+	case tcell.KeyTab:
+		lt.SetFilter(Filter{Text: lt.filter, Fuzzy: !lt.fuzzy})
+		return true
+	case tcell.KeyRune:
+		if ev.Modifiers()&^tcell.ModShift == tcell.ModNone {
+			lt.setFilter(lt.filter + string(ev.Rune()))
+			return true
+		}
+	}
+	return false
+}
+
 func (lt *ListTable) Next() {
 	lt.SelectIndex(lt.selectedRowIndex + 1)
 }
@@ -652,7 +1167,7 @@ func (lt *ListTable) Home() {
 }
 
 func (lt *ListTable) End() {
-	lt.SelectIndex(len(lt.table.rows) - 1)
+	lt.SelectIndex(lt.rows.Matched() - 1)
 }
 
 func (lt *ListTable) Right() {
@@ -664,12 +1179,13 @@ func (lt *ListTable) Left() {
 }
 
 func (lt *ListTable) SelectIndex(index int) {
-	if len(lt.table.rows) == 0 {
+	count := lt.rows.Matched()
+	if count == 0 {
 		return
 	}
 
-	if index > len(lt.table.rows)-1 {
-		index = len(lt.table.rows) - 1
+	if index > count-1 {
+		index = count - 1
 	}
 	if index < 0 {
 		index = 0
@@ -681,13 +1197,13 @@ func (lt *ListTable) SelectIndex(index int) {
 	} else {
 		delta = -1
 	}
-	row := lt.table.rows[index]
+	row := lt.rows.MatchedAt(index).row
 	for !row.Enabled() {
 		index += delta
-		if index < 0 || index >= len(lt.table.rows) {
+		if index < 0 || index >= count {
 			return
 		}
-		row = lt.table.rows[index]
+		row = lt.rows.MatchedAt(index).row
 	}
 	lt.selectedId = row.Id()
 	if lt.selectedRowIndex == index {
@@ -713,8 +1229,8 @@ func (lt *ListTable) SelectId(id string) {
 }
 
 func (lt *ListTable) reindexSelection() {
-	if index, ok := lt.rowIndex[lt.selectedId]; ok {
-		lt.SelectIndex(index)
+	if n := lt.rows.Get(lt.selectedId); n != nil && n.matched {
+		lt.SelectIndex(lt.rows.MatchedIndex(n))
 	} else {
 		lt.SelectIndex(0)
 	}
@@ -733,6 +1249,38 @@ func (lt *ListTable) SetLeft(index int) {
 	lt.leftCell = index
 }
 
+// Position is the selected row and scroll offsets of the table
+type Position struct {
+	RowId string
+	Top   int
+	Left  int
+}
+
+// Position returns the selected row and scroll offsets
+func (lt *ListTable) Position() Position {
+	return Position{RowId: lt.selectedId, Top: lt.topRow, Left: lt.leftCell}
+}
+
+// SetPosition selects the row and scrolls the table. While rows are loading, position is set once the row is loaded
+func (lt *ListTable) SetPosition(position Position) {
+	lt.position = &position
+	lt.applyPosition()
+}
+
+// applyPosition sets pending position when its row is there, or when loading is over and the row is gone
+func (lt *ListTable) applyPosition() {
+	position := lt.position
+	if position == nil || lt.loading && lt.rows.Get(position.RowId) == nil {
+		return
+	}
+	lt.position = nil
+	lt.SelectId(position.RowId)
+	if index := lt.selectedRowIndex; index >= position.Top && index < position.Top+lt.tableHeight() {
+		lt.topRow = position.Top
+	}
+	lt.SetLeft(position.Left)
+}
+
 func (lt *ListTable) SetView(view views.View) {
 	lt.view = view
 	lt.preloader.SetView(view)
@@ -755,15 +1303,6 @@ func (lt *ListTable) Size() (int, int) {
 	return w, h
 }
 
-func (lt *ListTable) getRowIndex(r commander.Row) int {
-	for i, row := range lt.rows {
-		if strings.Compare(r.Id(), row.Id()) != 1 {
-			return i
-		}
-	}
-	return 0
-}
-
 func (lt *ListTable) setAgeCol() {
 	for i, n := range lt.columns {
 		if n == "Age" {
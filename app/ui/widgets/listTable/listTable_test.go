@@ -0,0 +1,349 @@
+package listTable
+
+import (
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loop is a fake UI event loop. Functions posted from any goroutine, including the loop itself,
+// are queued and run one by one on the loop goroutine
+type loop struct {
+	sync.Mutex
+	queue  []func()
+	notify chan struct{}
+	done   chan struct{}
+}
+
+func newLoop() *loop {
+	l := &loop{
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case <-l.notify:
+			case <-l.done:
+				return
+			}
+			l.Lock()
+			funcs := l.queue
+			l.queue = nil
+			l.Unlock()
+			for _, f := range funcs {
+				f()
+			}
+		}
+	}()
+	return l
+}
+
+func (l *loop) UpdateScreen() {}
+
+func (l *loop) Resize() {}
+
+func (l *loop) PostFunc(f func()) {
+	l.Lock()
+	l.queue = append(l.queue, f)
+	l.Unlock()
+	select {
+	case l.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run runs the function on the loop and waits for it to finish
+func (l *loop) run(f func()) {
+	finished := make(chan struct{})
+	l.PostFunc(func() {
+		f()
+		close(finished)
+	})
+	<-finished
+}
+
+func (l *loop) stop() {
+	close(l.done)
+}
+
+func newTestTable(t *testing.T, format TableFormat) (*ListTable, commander.RowProvider, *loop) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(80, 25)
+	l := newLoop()
+	prov := make(commander.RowProvider)
+	lt := NewListTable(prov, format, l)
+	l.run(func() {
+		lt.SetView(views.NewViewPort(screen, 0, 0, 80, 25))
+		lt.OnShow()
+	})
+	t.Cleanup(func() {
+		l.run(lt.OnHide)
+		l.stop()
+		screen.Fini()
+	})
+	return lt, prov, l
+}
+
+func row(id string, cells ...string) commander.Row {
+	return commander.NewSimpleRow(id, append([]string{id}, cells...), true)
+}
+
+// waitRows waits until the table has the expected number of rows. Table is drawn meanwhile, like the screen does
+func waitRows(t *testing.T, lt *ListTable, l *loop, expected int) []commander.Row {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		var rows []commander.Row
+		l.run(func() {
+			lt.Draw()
+			rows = lt.Rows()
+		})
+		if len(rows) == expected {
+			return rows
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("table has %d rows, expected %d", len(rows), expected)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// TestListTableConcurrentUpdates feeds batches of operations from several goroutines while the table is
+// drawn, filtered, sorted and navigated on the UI loop. It is meant to be run with -race
+func TestListTableConcurrentUpdates(t *testing.T) {
+	const (
+		producers = 4
+		rowsEach  = 200
+		rounds    = 20
+	)
+	lt, prov, l := newTestTable(t, WithHeaders|WithFilter|WithSort)
+	prov <- []commander.Operation{&commander.OpSetColumns{Columns: []string{"Name", "Value"}}}
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			id := func(i int) string {
+				return fmt.Sprintf("row-%d-%03d", p, i)
+			}
+			for round := 0; round < rounds; round++ {
+				var ops []commander.Operation
+				for i := 0; i < rowsEach; i++ {
+					ops = append(ops, &commander.OpAdded{Row: row(id(i), fmt.Sprint(round)), SortById: true})
+				}
+				prov <- ops
+				ops = nil
+				for i := 0; i < rowsEach; i++ {
+					ops = append(ops, &commander.OpModified{Row: row(id(i), fmt.Sprint(round, "-", i))})
+				}
+				prov <- ops
+				ops = nil
+				// Even rows are deleted, so only odd ones are left in the end
+				for i := 0; i < rowsEach; i += 2 {
+					ops = append(ops, &commander.OpDeleted{RowId: id(i)})
+				}
+				prov <- ops
+			}
+		}(p)
+	}
+
+	stop := make(chan struct{})
+	uiDone := make(chan struct{})
+	go func() {
+		defer close(uiDone)
+		keys := []tcell.Key{tcell.KeyDown, tcell.KeyPgDn, tcell.KeyUp, tcell.KeyEnd, tcell.KeyHome}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			l.run(func() {
+				lt.HandleEvent(tcell.NewEventKey(keys[i%len(keys)], 0, tcell.ModNone))
+				switch i % 50 {
+				case 10:
+					lt.SetFilter(Filter{Text: "row-1"})
+				case 20:
+					lt.SetFilter(Filter{Text: "r1", Fuzzy: true})
+				case 30:
+					lt.SetFilter(Filter{})
+				case 40:
+					lt.CycleSort()
+				}
+				lt.Draw()
+			})
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	<-uiDone
+	l.run(func() {
+		lt.SetFilter(Filter{})
+		lt.SortBy("", false)
+	})
+	rows := waitRows(t, lt, l, producers*rowsEach/2)
+	for _, r := range rows {
+		var p, i int
+		if _, err := fmt.Sscanf(r.Id(), "row-%d-%03d", &p, &i); err != nil || i%2 == 0 {
+			t.Fatalf("unexpected row %s", r.Id())
+		}
+		if expected := fmt.Sprint(rounds-1, "-", i); r.Cells()[1] != expected {
+			t.Fatalf("row %s has value %s, expected %s", r.Id(), r.Cells()[1], expected)
+		}
+	}
+}
+
+// TestListTableHideShow hides and shows the table again and again while operations keep coming, like refresh
+// and namespace switches do. It is meant to be run with -race
+func TestListTableHideShow(t *testing.T) {
+	const rows = 500
+	lt, prov, l := newTestTable(t, WithHeaders)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		prov <- []commander.Operation{&commander.OpSetColumns{Columns: []string{"Name", "Value"}}}
+		for i := 0; i < rows; i++ {
+			id := fmt.Sprintf("row-%03d", i)
+			prov <- []commander.Operation{
+				&commander.OpAdded{Row: row(id, "added"), SortById: true},
+				&commander.OpModified{Row: row(id, "modified")},
+			}
+		}
+	}()
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		l.run(func() {
+			lt.OnHide()
+			lt.OnShow()
+			lt.Draw()
+		})
+	}
+	for _, r := range waitRows(t, lt, l, rows) {
+		if r.Cells()[1] != "modified" {
+			t.Fatalf("row %s has value %s", r.Id(), r.Cells()[1])
+		}
+	}
+}
+
+func rowIds(rows []commander.Row) string {
+	var ids []string
+	for _, r := range rows {
+		ids = append(ids, r.Id())
+	}
+	return fmt.Sprint(ids)
+}
+
+// TestListTableRestoresOrder checks that rows get back to the order they were inserted in once the fuzzy filter
+// or sorting is cleared, including rows inserted at a fixed index and rows added while the filter was set
+func TestListTableRestoresOrder(t *testing.T) {
+	lt, _, l := newTestTable(t, WithHeaders|WithFilter|WithSort)
+	index := func(i int) *int {
+		return &i
+	}
+	l.run(func() {
+		lt.Apply([]commander.Operation{
+			&commander.OpSetColumns{Columns: []string{"Name"}},
+			&commander.OpAdded{Row: row("c")},
+			&commander.OpAdded{Row: row("a")},
+			&commander.OpAdded{Row: row("header"), Index: index(0)},
+			&commander.OpAdded{Row: row("b"), SortById: true},
+		})
+	})
+	expected := "[header c a b]"
+	if ids := rowIds(waitRows(t, lt, l, 4)); ids != expected {
+		t.Fatalf("unexpected order %s, expected %s", ids, expected)
+	}
+	l.run(func() {
+		lt.SetFilter(Filter{Text: "a", Fuzzy: true})
+		lt.Apply([]commander.Operation{
+			&commander.OpAdded{Row: row("first"), Index: index(0)},
+			&commander.OpAdded{Row: row("last")},
+		})
+		lt.SetFilter(Filter{})
+	})
+	expected = "[first header c a b last]"
+	if ids := rowIds(waitRows(t, lt, l, 6)); ids != expected {
+		t.Fatalf("unexpected order %s after clearing the filter, expected %s", ids, expected)
+	}
+	l.run(func() {
+		lt.SortBy("Name", true)
+		lt.SortBy("", false)
+	})
+	if ids := rowIds(waitRows(t, lt, l, 6)); ids != expected {
+		t.Fatalf("unexpected order %s after clearing the sort, expected %s", ids, expected)
+	}
+}
+
+// TestListTableHandleAction checks that actions run directly, like from the palette, do the same as their keys
+func TestListTableHandleAction(t *testing.T) {
+	lt, _, l := newTestTable(t, WithHeaders|WithFilter)
+	var described []string
+	lt.BindOnAction(func(row commander.Row, a *actions.Action) bool {
+		if a == actDescribe {
+			described = append(described, row.Id())
+			return true
+		}
+		return false
+	}, actions.Resource)
+	l.run(func() {
+		lt.Apply([]commander.Operation{
+			&commander.OpSetColumns{Columns: []string{"Name"}},
+			&commander.OpAdded{Row: row("a")},
+			&commander.OpAdded{Row: row("b")},
+		})
+		lt.HandleAction(ActDown)
+		lt.HandleAction(actDescribe)
+		lt.HandleEvent(ActUp.Keys()[0].Event())
+		lt.HandleEvent(actDescribe.Keys()[0].Event())
+		lt.HandleAction(ActFilter)
+		// Filter is being typed, but the action still runs instead of typing its key
+		lt.HandleAction(actDescribe)
+	})
+	if expected := "[b a a]"; fmt.Sprint(described) != expected {
+		t.Errorf("described %v, expected %s", described, expected)
+	}
+	if lt.filter != "" {
+		t.Errorf("action is typed into the filter: %q", lt.filter)
+	}
+}
+
+// TestListTableDoubleClick checks that double click runs the double click action rather than a key
+func TestListTableDoubleClick(t *testing.T) {
+	lt, _, l := newTestTable(t, WithHeaders)
+	var run []string
+	lt.BindOnAction(func(row commander.Row, a *actions.Action) bool {
+		run = append(run, a.Id+" "+row.Id())
+		return true
+	}, actions.Resource)
+	l.run(func() {
+		lt.Apply([]commander.Operation{
+			&commander.OpSetColumns{Columns: []string{"Name"}},
+			&commander.OpAdded{Row: row("a")},
+			&commander.OpAdded{Row: row("b")},
+		})
+		_, first := lt.lines()
+		lt.click(0, first+1)
+		lt.click(0, first+1)
+		lt.SetDoubleClickAction(actDescribe)
+		lt.click(0, first)
+		lt.click(0, first)
+	})
+	if expected := "[" + actDescribe.Id + " a]"; fmt.Sprint(run) != expected {
+		t.Errorf("double clicks run %v, expected %s", run, expected)
+	}
+}
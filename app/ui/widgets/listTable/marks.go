@@ -0,0 +1,116 @@
+package listTable
+
+import (
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// ToggleMark marks the selected row or removes the mark and moves selection to the next row
+func (lt *ListTable) ToggleMark() {
+	row := lt.SelectedRow()
+	if row == nil {
+		return
+	}
+	if _, ok := lt.marked[row.Id()]; ok {
+		delete(lt.marked, row.Id())
+	} else {
+		lt.marked[row.Id()] = struct{}{}
+	}
+	lt.Next()
+}
+
+// MarkMatched marks all rows matching the filter
+func (lt *ListTable) MarkMatched() {
+	lt.rows.Each(func(n *rowNode) {
+		if n.matched {
+			lt.marked[n.row.Id()] = struct{}{}
+		}
+	})
+}
+
+// InvertMarks inverts marks of rows matching the filter
+func (lt *ListTable) InvertMarks() {
+	lt.rows.Each(func(n *rowNode) {
+		if !n.matched {
+			return
+		}
+		if _, ok := lt.marked[n.row.Id()]; ok {
+			delete(lt.marked, n.row.Id())
+		} else {
+			lt.marked[n.row.Id()] = struct{}{}
+		}
+	})
+}
+
+func (lt *ListTable) ClearMarks() {
+	lt.marked = make(map[string]struct{})
+}
+
+// MarkedRows returns marked rows in table order, including ones hidden by the filter
+func (lt *ListTable) MarkedRows() []commander.Row {
+	var rows []commander.Row
+	if len(lt.marked) == 0 {
+		return nil
+	}
+	lt.rows.Each(func(n *rowNode) {
+		if _, ok := lt.marked[n.row.Id()]; ok {
+			rows = append(rows, n.row)
+		}
+	})
+	return rows
+}
+
+// Targets returns rows to act on: marked rows or the selected one if nothing is marked
+func (lt *ListTable) Targets() []commander.Row {
+	if rows := lt.MarkedRows(); len(rows) > 0 {
+		return rows
+	}
+	if row := lt.SelectedRow(); row != nil {
+		return []commander.Row{row}
+	}
+	return nil
+}
+
+func (lt *ListTable) isMarked(row commander.Row) bool {
+	_, ok := lt.marked[row.Id()]
+	return ok
+}
+
+// markedStyle applies marked row style on top of the row style, so selection is still visible
+func (lt *ListTable) markedStyle(style tcell.Style) tcell.Style {
+	fg, _, attrs := lt.stMarked.Style().Decompose()
+	return style.Foreground(fg).Bold(attrs&tcell.AttrBold != 0)
+}
+
+// handleMarkAction runs actions which mark rows
+func (lt *ListTable) handleMarkAction(a *actions.Action) bool {
+	switch a {
+	case actMark:
+		lt.ToggleMark()
+	case actMarkMatched:
+		lt.MarkMatched()
+	case actInvertMarks:
+		lt.InvertMarks()
+	case actClearMarks:
+		lt.ClearMarks()
+	default:
+		return false
+	}
+	return true
+}
+
+// drawMarks draws the number of marked rows to the left of x in the top line
+func (lt *ListTable) drawMarks(x int) {
+	if len(lt.marked) == 0 {
+		return
+	}
+	str := fmt.Sprintf("%d marked ", len(lt.marked))
+	x -= runewidth.StringWidth(str)
+	for _, ch := range str {
+		lt.view.SetContent(x, 0, ch, nil, lt.stMarked.Style())
+		x += runewidth.RuneWidth(ch)
+	}
+}
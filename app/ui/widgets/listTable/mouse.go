@@ -0,0 +1,119 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
+	"github.com/gdamore/tcell"
+)
+
+// Number of rows scrolled by a wheel step
+const wheelRows = 3
+
+// handleMouse handles clicks and wheel motion. The event is in coordinates of the view's parent
+func (lt *ListTable) handleMouse(ev *tcell.EventMouse) bool {
+	local, ok := mouse.Local(ev, lt.view)
+	if !ok {
+		return false
+	}
+	x, y := local.Position()
+	buttons := local.Buttons()
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		lt.Scroll(-wheelRows)
+	case buttons&tcell.WheelDown != 0:
+		lt.Scroll(wheelRows)
+	case buttons&tcell.WheelLeft != 0:
+		lt.Left()
+	case buttons&tcell.WheelRight != 0:
+		lt.Right()
+	case buttons&tcell.Button1 != 0:
+		lt.click(x, y)
+	}
+	return true
+}
+
+// lines returns line of headers, -1 if there are none, and line of the first row
+func (lt *ListTable) lines() (int, int) {
+	y := 0
+	if lt.caption != "" {
+		y++
+	}
+	if lt.filterMode || lt.filter != "" {
+		y++
+	}
+	if lt.format.Has(WithHeaders) {
+		return y, y + 1
+	}
+	return -1, y
+}
+
+// columnAt returns index of the column drawn at x, or -1
+func (lt *ListTable) columnAt(x int) int {
+	x += lt.leftCell
+	for i, size := range lt.getColumnSizes() {
+		if x < size {
+			return i
+		}
+		x -= size + columnSeparatorLen
+		if x < 0 {
+			// Separator belongs to no column
+			return -1
+		}
+	}
+	return -1
+}
+
+// click selects the row under the mouse. Second click on the same row runs the double click action,
+// and click on a header sorts by the column
+func (lt *ListTable) click(x, y int) {
+	header, first := lt.lines()
+	if y == header {
+		if column := lt.columnAt(x); column != -1 && lt.format.Has(WithSort) {
+			lt.clickHeader(column)
+		}
+		return
+	}
+	index := lt.topRow + y - first
+	if y < first || index >= lt.rows.Matched() {
+		return
+	}
+	lt.SelectIndex(index)
+	if lt.selectedRowIndex != index {
+		// Disabled rows can't be selected
+		return
+	}
+	if lt.clicks.Click(index) && lt.doubleClick != nil {
+		lt.HandleAction(lt.doubleClick)
+	}
+}
+
+// clickHeader sorts by the column in ascending and then descending order, and the third click restores
+// the original order
+func (lt *ListTable) clickHeader(column int) {
+	switch {
+	case column != lt.sortCol:
+		lt.SetSort(column, false)
+	case !lt.sortDesc:
+		lt.SetSort(column, true)
+	default:
+		lt.SetSort(-1, false)
+	}
+}
+
+// Scroll moves the viewport by the number of rows. Selection is kept within the viewport
+func (lt *ListTable) Scroll(delta int) {
+	height := lt.tableHeight()
+	maxTop := lt.rows.Matched() - height
+	top := lt.topRow + delta
+	if top > maxTop {
+		top = maxTop
+	}
+	if top < 0 {
+		top = 0
+	}
+	lt.topRow = top
+	if lt.selectedRowIndex < top {
+		lt.SelectIndex(top)
+	} else if bottom := top + height - 1; lt.selectedRowIndex > bottom {
+		lt.SelectIndex(bottom)
+	}
+}
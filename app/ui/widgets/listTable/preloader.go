@@ -5,6 +5,7 @@ import (
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	"strconv"
 	"time"
 )
 
@@ -24,6 +25,8 @@ type preloader struct {
 	style     tcell.Style
 	updater   commander.ScreenUpdater
 	preloader *preloader
+	loaded    int
+	total     int
 }
 
 func NewPreloader(updater commander.ScreenUpdater) *preloader {
@@ -34,30 +37,71 @@ func NewPreloader(updater commander.ScreenUpdater) *preloader {
 }
 
 func (p *preloader) Start() {
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
 	p.phase = 0
+	p.loaded = 0
+	p.total = 0
 	p.ticker = time.NewTicker(time.Millisecond * 200)
+	ticker := p.ticker
 	go func() {
-		for range p.ticker.C {
-			p.phase++
-			if p.phase >= len(phases) {
-				p.phase = 0
-			}
-			p.updater.UpdateScreen()
+		for range ticker.C {
+			p.updater.PostFunc(p.nextPhase)
 		}
 	}()
 }
 
+// nextPhase advances preloader animation. Must be called on the UI event loop
+func (p *preloader) nextPhase() {
+	if p.phase == -1 {
+		return
+	}
+	p.phase++
+	if p.phase >= len(phases) {
+		p.phase = 0
+	}
+}
+
 func (p *preloader) Stop() {
-	p.ticker.Stop()
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
 	p.phase = -1
 	p.updater.UpdateScreen()
 }
 
+// SetProgress sets the number of loaded items and estimated total number of items
+func (p *preloader) SetProgress(loaded int, total int) {
+	p.loaded = loaded
+	p.total = total
+}
+
 func (p preloader) Draw() {
 	if p.phase == -1 {
 		return
 	}
-	p.view.SetContent(0, 0, phases[p.phase], nil, tcell.StyleDefault.Background(tcell.ColorTeal).Foreground(tcell.ColorBlack))
+	style := tcell.StyleDefault.Background(tcell.ColorTeal).Foreground(tcell.ColorBlack)
+	p.view.SetContent(0, 0, phases[p.phase], nil, style)
+	if p.loaded == 0 {
+		return
+	}
+	progress := " " + formatCount(p.loaded)
+	if p.total > p.loaded {
+		progress += " / ~" + formatCount(p.total)
+	}
+	for x, ch := range []rune(progress + " ") {
+		p.view.SetContent(x+1, 0, ch, nil, style)
+	}
+}
+
+// formatCount renders number with thousands separators: 12,000
+func formatCount(n int) string {
+	str := strconv.Itoa(n)
+	for i := len(str) - 3; i > 0; i -= 3 {
+		str = str[:i] + "," + str[i:]
+	}
+	return str
 }
 
 func (p preloader) Resize() {
@@ -1,14 +1,12 @@
 package listTable
 
 import (
-	"context"
 	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/atotto/clipboard"
-	"github.com/gdamore/tcell"
-	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"io/ioutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
 )
 
 type ResourceListTable struct {
@@ -16,11 +14,14 @@ type ResourceListTable struct {
 
 	container commander.ResourceContainer
 	resource  *commander.Resource
+	selector  commander.Selector
 
-	stopWatchCh chan struct{}
-	rowProvider commander.RowProvider
-	format      TableFormat
-	extraRows   map[int]commander.Row
+	stopWatchCh  chan struct{}
+	subscription commander.Subscription
+	rowProvider  commander.RowProvider
+	format       TableFormat
+	layout       ColumnLayout
+	extraRows    map[int]commander.Row
 }
 
 func NewResourceListTable(container commander.ResourceContainer, resource *commander.Resource, format TableFormat) *ResourceListTable {
@@ -29,10 +30,17 @@ func NewResourceListTable(container commander.ResourceContainer, resource *comma
 		resource:    resource,
 		rowProvider: make(commander.RowProvider),
 		format:      format,
+		layout:      ColumnLayout{Wide: format.Has(Wide)},
+	}
+	if !format.Has(NameOnly) {
+		container.StateStore().Load(layoutKey(resource.Gk), &resourceLt.layout)
 	}
 	resourceLt.ListTable = NewListTable(resourceLt.rowProvider, format, container.ScreenUpdater())
+	resourceLt.ListTable.SetColumnFunc(resourceLt.columns)
+	resourceLt.ListTable.SetFilterScope(container.CurrentNamespace)
 	if !format.Has(NoActions) {
-		resourceLt.BindOnKeyPress(resourceLt.OnKeyPress)
+		resourceLt.BindOnAction(resourceLt.OnAction, actions.Resource)
+		resourceLt.SetDoubleClickAction(actDescribe)
 	}
 	return resourceLt
 }
@@ -41,183 +49,211 @@ func (r *ResourceListTable) SetExtraRows(rows map[int]commander.Row) {
 	r.extraRows = rows
 }
 
-func (r *ResourceListTable) OnKeyPress(row commander.Row, event *tcell.EventKey) bool {
-	switch event.Key() {
-	case tcell.KeyDelete:
-		go r.delete(row)
-		return true
-	case tcell.KeyCtrlR:
+// SetSelector applies server-side selector and restarts the list
+func (r *ResourceListTable) SetSelector(selector commander.Selector) {
+	r.selector = selector
+	r.rememberSelector(selector)
+	if selector.Empty() {
+		r.SetCaption("")
+	} else {
+		r.SetCaption("Selector: " + selector.String())
+	}
+	if r.IsVisible() {
 		r.OnHide()
 		r.OnShow()
-		return true
-	}
-	switch event.Rune() {
-	case 'd':
-		go r.describe(row)
-		return true
-	case 'e':
-		go r.edit(row)
-		return true
-	case 'c':
-		go r.copy(row)
-		return true
 	}
-	return false
 }
 
-func (r *ResourceListTable) OnShow() {
-	r.stopWatchCh = make(chan struct{})
-	go r.provideRows()
-	r.ListTable.OnShow()
+func (r *ResourceListTable) Selector() commander.Selector {
+	return r.selector
 }
 
-func (r *ResourceListTable) OnHide() {
-	r.ListTable.OnHide()
-	close(r.stopWatchCh)
+func (r *ResourceListTable) pickSelector() {
+	prompt := newSelectorPrompt(r.selector, loadRecentSelectors(r.container.StateStore(), r.resource.Gk), func(selector commander.Selector) {
+		r.container.FocusManager().Blur()
+		r.SetSelector(selector)
+	})
+	r.container.ShowPopup("Selector", prompt)
 }
 
-func (r *ResourceListTable) watch(restartChan chan bool) {
-	watcher, err := r.container.Client().WatchAsTable(context.TODO(), r.resource, r.container.CurrentNamespace())
+// rememberSelector saves the selector among recent ones of the resource kind
+func (r *ResourceListTable) rememberSelector(selector commander.Selector) {
+	store := r.container.StateStore()
+	recent := loadRecentSelectors(store, r.resource.Gk)
+	if selector.Empty() || len(recent) > 0 && recent[0] == selector {
+		return
+	}
+	err := store.Save(recentSelectorsKey(r.resource.Gk), addRecentSelector(recent, selector))
+	if err != nil {
+		r.container.Status().Error(err)
+	}
+}
+
+// SetLayout shows columns according to the layout and saves it for the resource kind
+func (r *ResourceListTable) SetLayout(layout ColumnLayout) {
+	r.layout = layout
+	r.SetColumnFunc(r.columns)
+	err := r.container.StateStore().Save(layoutKey(r.resource.Gk), layout)
 	if err != nil {
 		r.container.Status().Error(err)
+	}
+}
+
+func (r *ResourceListTable) chooseColumns() {
+	_, definitions := r.AllColumns()
+	if len(definitions) == 0 {
 		return
 	}
+	chooser := newColumnChooser(definitions, r.layout, func(layout ColumnLayout) {
+		r.container.FocusManager().Blur()
+		r.SetLayout(layout)
+	})
+	r.container.ShowPopup("Columns", chooser)
+}
 
-	go func() {
-		defer watcher.Stop()
-		for {
-			select {
-			case <-r.stopWatchCh:
-				close(restartChan)
-				return
-			case event, ok := <-watcher.ResultChan():
-				if !ok {
-					restartChan <- true
-					return
-				}
-				if event.Type == watch.Error {
-					err := apierrs.FromObject(event.Object)
-					r.container.Status().Error(fmt.Errorf("error while watching: %w", err))
-					restartChan <- true
-					return
-				}
-				var ops []commander.Operation
-				rows, err := r.extractRows(event)
-				if err != nil {
-					r.container.Status().Error(err)
-					close(restartChan)
-					return
-				}
-				switch event.Type {
-				case watch.Added:
-					for _, row := range rows {
-						ops = append(ops, &commander.OpAdded{Row: row, SortById: true})
-					}
-				case watch.Modified:
-					for _, row := range rows {
-						ops = append(ops, &commander.OpModified{Row: row})
-					}
-				case watch.Deleted:
-					for _, row := range rows {
-						ops = append(ops, &commander.OpDeleted{RowId: row.Id()})
-					}
-				}
-				if len(ops) > 0 {
-					r.rowProvider <- ops
-				}
-			}
+func (r *ResourceListTable) pickFilter() {
+	filters := loadSavedFilters(r.container.StateStore())
+	picker := newSavedFiltersPicker(r.Filter(), filters, func(filter Filter) {
+		r.container.FocusManager().Blur()
+		r.SetFilter(filter)
+	}, func(filters []SavedFilter) error {
+		err := r.container.StateStore().Save(savedFiltersKey, filters)
+		if err != nil {
+			r.container.Status().Error(err)
 		}
-	}()
+		return err
+	})
+	r.container.ShowPopup("Filters", picker)
 }
 
-func (r *ResourceListTable) provideRows() {
-	r.rowProvider <- []commander.Operation{&commander.OpInitStart{}}
+func (r *ResourceListTable) pickExport() {
+	prompt := newExportPrompt(func(format ExportFormat, file string) {
+		r.container.FocusManager().Blur()
+		r.export(format, file)
+	})
+	r.container.ShowPopup("Export", prompt)
+}
 
-	columns, rows, err := r.loadResourceRows()
-	var ops []commander.Operation
+// export writes the table to the file or to the clipboard if file is empty
+func (r *ResourceListTable) export(format ExportFormat, file string) {
+	data, err := r.Export(format)
 	if err != nil {
-		r.rowProvider <- []commander.Operation{&commander.OpInitFinished{}}
 		r.container.Status().Error(err)
 		return
 	}
-	ops = append(ops,
-		&commander.OpClear{},
-		&commander.OpSetColumns{Columns: columns},
-	)
-	for _, row := range rows {
-		ops = append(ops, &commander.OpAdded{Row: row})
-	}
-	for index, row := range r.extraRows {
-		ops = append(ops, &commander.OpAdded{Row: row, Index: &index})
+	if file == "" {
+		err = clipboard.WriteAll(string(data))
+		file = "the clipboard"
+	} else {
+		err = ioutil.WriteFile(file, data, 0644)
 	}
-	ops = append(ops, &commander.OpInitFinished{})
-	r.rowProvider <- ops
-	if r.format.Has(NoWatch) {
+	if err != nil {
+		r.container.Status().Error(err)
 		return
 	}
-	restartWatcher := make(chan bool)
-	go r.watch(restartWatcher)
+	r.container.Status().Info(fmt.Sprintf("Exported %s to %s", format, file))
+}
+
+func (r *ResourceListTable) OnAction(row commander.Row, a *actions.Action) bool {
+	switch a {
+	case actDescribe:
+		go r.describe(row)
+	case actEdit:
+		go r.edit(row)
+	case actCopy:
+		go r.bulkCopy(r.targets())
+	case actDelete:
+		go r.bulkDelete(r.targets())
+	case actRestart:
+		go r.bulkRestart(r.targets())
+	case actColumns:
+		r.chooseColumns()
+	case actSelector:
+		r.pickSelector()
+	case actFilters:
+		r.pickFilter()
+	case actLabels:
+		r.pickLabels(r.targets())
+	case actExport:
+		r.pickExport()
+	case actRefresh:
+		r.OnHide()
+		r.container.ResourceCache().Refresh(r.resource, r.container.CurrentNamespace(), r.selector)
+		r.OnShow()
+	default:
+		return false
+	}
+	return true
+}
+
+func (r *ResourceListTable) OnShow() {
+	r.stopWatchCh = make(chan struct{})
+	r.subscription = r.container.ResourceCache().Subscribe(r.resource, r.container.CurrentNamespace(), r.selector)
+	go r.provideRows(r.subscription, r.stopWatchCh)
+	// Rows are loaded again, and position set meanwhile waits for them
+	r.loading = true
+	r.ListTable.OnShow()
+}
+
+func (r *ResourceListTable) OnHide() {
+	r.ListTable.OnHide()
+	close(r.stopWatchCh)
+	// Subscription is closed right away, so the informer is released before the table is shown again
+	r.subscription.Close()
+}
+
+// provideRows passes shared operations to the table until it gets hidden
+func (r *ResourceListTable) provideRows(sub commander.Subscription, stopCh chan struct{}) {
+	defer sub.Close()
 	for {
 		select {
-		case _, ok := <-restartWatcher:
+		case <-stopCh:
+			return
+		case ops, ok := <-sub.Provider():
 			if !ok {
 				return
 			}
-			go r.watch(restartWatcher)
+			ops, finished := r.prepareOps(ops)
+			select {
+			case r.rowProvider <- ops:
+			case <-stopCh:
+				return
+			}
+			if finished && r.format.Has(NoWatch) {
+				return
+			}
 		}
 	}
 }
 
-func (r *ResourceListTable) extractRows(event watch.Event) ([]*commander.KubernetesRow, error) {
-	var rows []*commander.KubernetesRow
-	table, ok := event.Object.(*metav1.Table)
-	if ok {
-		for _, row := range table.Rows {
-			k8sRow, err := commander.NewKubernetesRow(row)
-			if err != nil {
-				return nil, err
+// prepareOps adds extra rows to shared operations. Reports whether initial loading is finished
+func (r *ResourceListTable) prepareOps(ops []commander.Operation) ([]commander.Operation, bool) {
+	var prepared []commander.Operation
+	finished := false
+	for _, operation := range ops {
+		if _, ok := operation.(*commander.OpInitFinished); ok {
+			for index, row := range r.extraRows {
+				index := index
+				prepared = append(prepared, &commander.OpAdded{Row: row, Index: &index})
 			}
-			rows = append(rows, k8sRow)
+			finished = true
 		}
+		prepared = append(prepared, operation)
 	}
-	return rows, nil
+	return prepared, finished
 }
 
-func (r *ResourceListTable) loadResourceRows() ([]string, []commander.Row, error) {
-	table, err := r.container.Client().ListAsTable(context.TODO(), r.resource, r.container.CurrentNamespace())
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var cols []string
-	var rows []commander.Row
-	var colIds []int
-
-	for colId, col := range table.ColumnDefinitions {
-		add := false
-		switch {
-		case r.format&Wide != 0:
-			add = true
-		case r.format&Short != 0:
-			add = col.Priority == 0
-		case r.format&NameOnly != 0:
-			add = col.Name == "Name"
-		}
-		if add {
-			cols = append(cols, col.Name)
-			colIds = append(colIds, colId)
-		}
-	}
-
-	for _, row := range table.Rows {
-		k8sRow, err := commander.NewKubernetesRow(row)
-		if err != nil {
-			return nil, nil, err
+// columns picks columns according to the layout. Name-only tables show just the name
+func (r *ResourceListTable) columns(_ []string, definitions []metav1.TableColumnDefinition) []int {
+	if r.format.Has(NameOnly) {
+		for i, col := range definitions {
+			if col.Name == "Name" {
+				return []int{i}
+			}
 		}
-		rows = append(rows, k8sRow)
+		return nil
 	}
-
-	return cols, rows, nil
+	return r.layout.Columns(definitions)
 }
 
 func (r ResourceListTable) RowMetadata(row commander.Row) (*metav1.PartialObjectMetadata, error) {
@@ -257,41 +293,3 @@ func (r ResourceListTable) edit(row commander.Row) {
 		return
 	}
 }
-
-func (r ResourceListTable) copy(row commander.Row) {
-	metadata, err := r.RowMetadata(row)
-	if err != nil {
-		r.container.Status().Error(err)
-		return
-	}
-	err = clipboard.WriteAll(metadata.Name)
-	if err != nil {
-		r.container.Status().Error(err)
-		return
-	}
-	r.container.Status().Info(fmt.Sprintf("Resource name copied! '%s'", metadata.Name))
-}
-
-func (r ResourceListTable) delete(row commander.Row) {
-	metadata, err := r.RowMetadata(row)
-	if err != nil {
-		r.container.Status().Error(err)
-		return
-	}
-	var displayName string
-	if r.resource.Namespaced {
-		displayName = fmt.Sprintf("%s %s/%s", r.resource.Gvk.Kind, metadata.Namespace, metadata.Name)
-	} else {
-		displayName = fmt.Sprintf("%s %s", r.resource.Gvk.Kind, metadata.Name)
-	}
-	if r.container.Status().Confirm(fmt.Sprintf("You are about to delete %s. Are you sure? (y/N)", displayName)) {
-		err := r.container.Client().Delete(context.TODO(), r.resource, metadata.Namespace, metadata.Name)
-		if err != nil {
-			r.container.Status().Error(err)
-		} else {
-			r.container.Status().Info("Deleted.")
-		}
-	} else {
-		r.container.Status().Info("Cancelled.")
-	}
-}
@@ -0,0 +1,237 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	savedFiltersKey        = "filters"
+	savedFiltersInputWidth = 40
+)
+
+// SavedFilter is a filter saved under a name to be recalled later
+type SavedFilter struct {
+	Name string `json:"name"`
+	Filter
+}
+
+func loadSavedFilters(store commander.StateStore) []SavedFilter {
+	var filters []SavedFilter
+	store.Load(savedFiltersKey, &filters)
+	return filters
+}
+
+// saveFilter adds the filter to the list. Filter with the same name is replaced
+func saveFilter(filters []SavedFilter, saved SavedFilter) []SavedFilter {
+	for i, filter := range filters {
+		if filter.Name == saved.Name {
+			filters[i] = saved
+			return filters
+		}
+	}
+	return append(filters, saved)
+}
+
+func savedFiltersHint() string {
+	return actions.Hints(popup.ActApply.Hint("save or apply"), popup.ActRemove.Hint("remove"))
+}
+
+type SavedFiltersFunc func(filters []SavedFilter) error
+
+// savedFiltersPicker is a popup to save the current filter under a name and to recall saved ones
+type savedFiltersPicker struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view    views.View
+	name    *input.Input
+	current Filter
+	filters []SavedFilter
+	// Selected saved filter, -1 means name input
+	selected int
+	apply    func(filter Filter)
+	save     SavedFiltersFunc
+	clicks   mouse.DoubleClicks
+
+	stFilter commander.StyleComponent
+	stHint   commander.StyleComponent
+}
+
+func newSavedFiltersPicker(current Filter, filters []SavedFilter, apply func(filter Filter), save SavedFiltersFunc) *savedFiltersPicker {
+	p := &savedFiltersPicker{
+		Focusable: focus.NewFocusable(),
+		name:      input.NewInput("Save as: ", "", savedFiltersInputWidth),
+		current:   current,
+		filters:   filters,
+		selected:  -1,
+		apply:     apply,
+		save:      save,
+
+		stFilter: theme.NewComponent("filter", theme.Default),
+		stHint:   theme.NewComponent("hint", theme.Default.Underline(true)),
+	}
+	if current.Text == "" {
+		// There is nothing to save, so saved filters are picked right away
+		p.selected = 0
+	} else {
+		p.name.OnFocus()
+	}
+	return p
+}
+
+func (p *savedFiltersPicker) GetComponents() []commander.StyleComponent {
+	return append(p.name.GetComponents(), p.stFilter, p.stHint)
+}
+
+func (p *savedFiltersPicker) Draw() {
+	p.view.Fill(' ', theme.Default)
+	if p.current.Text != "" {
+		p.name.Draw()
+		p.drawLine(1, "Current: "+p.current.String(), p.stFilter.Style())
+	}
+	for i, filter := range p.filters {
+		style := p.stFilter.Style()
+		if i == p.selected {
+			style = style.Background(theme.ColorSelectedFocusedBackground)
+		}
+		p.drawLine(3+i, filter.Name+": "+filter.String(), style)
+	}
+	if len(p.filters) == 0 {
+		p.drawLine(3, "No saved filters", p.stFilter.Style())
+	}
+	p.drawLine(p.hintLine(), savedFiltersHint(), p.stHint.Style())
+}
+
+func (p *savedFiltersPicker) hintLine() int {
+	if len(p.filters) == 0 {
+		return 5
+	}
+	return 4 + len(p.filters)
+}
+
+func (p *savedFiltersPicker) drawLine(y int, str string, style tcell.Style) {
+	x := 0
+	for _, ch := range str {
+		p.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+}
+
+func (p *savedFiltersPicker) Resize() {
+}
+
+func (p *savedFiltersPicker) selectIndex(index int) {
+	if index >= len(p.filters) {
+		return
+	}
+	if index < 0 {
+		if p.current.Text == "" {
+			return
+		}
+		index = -1
+		p.name.OnFocus()
+	} else {
+		p.name.OnBlur()
+	}
+	p.selected = index
+}
+
+func (p *savedFiltersPicker) remove() {
+	if p.selected < 0 || p.selected >= len(p.filters) {
+		return
+	}
+	filters := append(append([]SavedFilter{}, p.filters[:p.selected]...), p.filters[p.selected+1:]...)
+	if p.save(filters) != nil {
+		return
+	}
+	p.filters = filters
+	if p.selected >= len(p.filters) {
+		p.selectIndex(len(p.filters) - 1)
+	}
+}
+
+// click selects the saved filter under the mouse, and double click applies it
+func (p *savedFiltersPicker) click(ev *tcell.EventMouse) bool {
+	local, ok := mouse.Local(ev, p.view)
+	if !ok || local.Buttons()&tcell.Button1 == 0 {
+		return ok
+	}
+	_, y := local.Position()
+	index := y - 3
+	if y == 0 && p.current.Text != "" {
+		p.selectIndex(-1)
+	} else if index >= 0 && index < len(p.filters) {
+		p.selectIndex(index)
+		if p.clicks.Click(index) {
+			p.apply(p.filters[index].Filter)
+		}
+	}
+	return true
+}
+
+func (p *savedFiltersPicker) HandleEvent(ev tcell.Event) bool {
+	if m, ok := ev.(*tcell.EventMouse); ok {
+		return p.click(m)
+	}
+	if e, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case popup.ActApply.Matches(e):
+			if p.selected >= 0 && p.selected < len(p.filters) {
+				p.apply(p.filters[p.selected].Filter)
+			} else if p.name.Text() != "" {
+				filters := saveFilter(p.filters, SavedFilter{Name: p.name.Text(), Filter: p.current})
+				if p.save(filters) == nil {
+					p.apply(p.current)
+				}
+			}
+			return true
+		case e.Key() == tcell.KeyDown:
+			p.selectIndex(p.selected + 1)
+			return true
+		case e.Key() == tcell.KeyUp:
+			p.selectIndex(p.selected - 1)
+			return true
+		case popup.ActRemove.Matches(e):
+			if p.selected >= 0 {
+				p.remove()
+				return true
+			}
+		}
+	}
+	if p.selected == -1 {
+		return p.name.HandleEvent(ev)
+	}
+	return false
+}
+
+func (p *savedFiltersPicker) SetView(view views.View) {
+	p.view = view
+	w, _ := view.Size()
+	p.name.SetView(views.NewViewPort(view, 0, 0, w, 1))
+}
+
+func (p *savedFiltersPicker) Size() (int, int) {
+	return p.MaxSize()
+}
+
+func (p *savedFiltersPicker) MaxSize() (int, int) {
+	w, _ := p.name.MaxSize()
+	if width := runewidth.StringWidth(savedFiltersHint()); width > w {
+		w = width
+	}
+	for _, filter := range p.filters {
+		if width := runewidth.StringWidth(filter.Name + ": " + filter.String()); width > w {
+			w = width
+		}
+	}
+	return w, p.hintLine() + 1
+}
@@ -0,0 +1,177 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/focus"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/input"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/popup"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	selectorHistoryLimit = 10
+	selectorInputWidth   = 40
+)
+
+func recentSelectorsKey(gk schema.GroupKind) string {
+	return "selectors/" + gk.String()
+}
+
+// loadRecentSelectors returns selectors recently used for the resource kind, the latest first
+func loadRecentSelectors(store commander.StateStore, gk schema.GroupKind) []commander.Selector {
+	var selectors []commander.Selector
+	store.Load(recentSelectorsKey(gk), &selectors)
+	return selectors
+}
+
+// addRecentSelector moves the selector to the top of recent ones, keeping at most selectorHistoryLimit of them
+func addRecentSelector(recent []commander.Selector, selector commander.Selector) []commander.Selector {
+	items := []commander.Selector{selector}
+	for _, item := range recent {
+		if item != selector && len(items) < selectorHistoryLimit {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+type SelectorFunc func(selector commander.Selector)
+
+type selectorPrompt struct {
+	views.WidgetWatchers
+	*focus.Focusable
+
+	view    views.View
+	labels  *input.Input
+	fields  *input.Input
+	recent  []commander.Selector
+	current int
+	apply   SelectorFunc
+
+	stRecent commander.StyleComponent
+}
+
+func newSelectorPrompt(selector commander.Selector, recent []commander.Selector, apply SelectorFunc) *selectorPrompt {
+	p := &selectorPrompt{
+		Focusable: focus.NewFocusable(),
+		labels:    input.NewInput("Labels: ", selector.Label, selectorInputWidth),
+		fields:    input.NewInput("Fields: ", selector.Field, selectorInputWidth),
+		recent:    recent,
+		current:   -1,
+		apply:     apply,
+
+		stRecent: theme.NewComponent("recent", theme.Default),
+	}
+	p.labels.OnFocus()
+	return p
+}
+
+func (p *selectorPrompt) GetComponents() []commander.StyleComponent {
+	return append(p.labels.GetComponents(), p.stRecent)
+}
+
+func (p *selectorPrompt) Draw() {
+	p.view.Fill(' ', theme.Default)
+	p.labels.Draw()
+	p.fields.Draw()
+	if len(p.recent) == 0 {
+		return
+	}
+	p.drawLine(3, "Recent (↑↓):", p.stRecent.Style().Underline(true))
+	for i, selector := range p.recent {
+		style := p.stRecent.Style()
+		if i == p.current {
+			style = style.Background(theme.ColorSelectedFocusedBackground)
+		}
+		p.drawLine(4+i, selector.String(), style)
+	}
+}
+
+func (p *selectorPrompt) drawLine(y int, str string, style tcell.Style) {
+	x := 0
+	for _, ch := range str {
+		p.view.SetContent(x, y, ch, nil, style)
+		x += runewidth.RuneWidth(ch)
+	}
+}
+
+func (p *selectorPrompt) Resize() {
+}
+
+func (p *selectorPrompt) activeInput() *input.Input {
+	if p.fields.IsFocused() {
+		return p.fields
+	}
+	return p.labels
+}
+
+func (p *selectorPrompt) switchInput() {
+	if p.labels.IsFocused() {
+		p.labels.OnBlur()
+		p.fields.OnFocus()
+	} else {
+		p.fields.OnBlur()
+		p.labels.OnFocus()
+	}
+}
+
+func (p *selectorPrompt) selectRecent(index int) {
+	if index < 0 || index >= len(p.recent) {
+		return
+	}
+	p.current = index
+	p.labels.SetText(p.recent[index].Label)
+	p.fields.SetText(p.recent[index].Field)
+}
+
+func (p *selectorPrompt) HandleEvent(ev tcell.Event) bool {
+	if e, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case popup.ActApply.Matches(e):
+			p.apply(commander.Selector{
+				Label: p.labels.Text(),
+				Field: p.fields.Text(),
+			})
+			return true
+		case popup.ActNext.Matches(e), popup.ActPrev.Matches(e):
+			p.switchInput()
+			return true
+		case e.Key() == tcell.KeyDown:
+			p.selectRecent(p.current + 1)
+			return true
+		case e.Key() == tcell.KeyUp:
+			p.selectRecent(p.current - 1)
+			return true
+		}
+	}
+	return p.activeInput().HandleEvent(ev)
+}
+
+func (p *selectorPrompt) SetView(view views.View) {
+	p.view = view
+	w, _ := view.Size()
+	p.labels.SetView(views.NewViewPort(view, 0, 0, w, 1))
+	p.fields.SetView(views.NewViewPort(view, 0, 1, w, 1))
+}
+
+func (p *selectorPrompt) Size() (int, int) {
+	return p.MaxSize()
+}
+
+func (p *selectorPrompt) MaxSize() (int, int) {
+	w, h := p.labels.MaxSize()
+	h = 2
+	if len(p.recent) > 0 {
+		h += 2 + len(p.recent)
+	}
+	for _, selector := range p.recent {
+		if width := runewidth.StringWidth(selector.String()); width > w {
+			w = width
+		}
+	}
+	return w, h
+}
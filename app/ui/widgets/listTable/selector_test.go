@@ -0,0 +1,39 @@
+package listTable
+
+import (
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/state"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecentSelectors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	store, err := state.NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods, deployments := schema.GroupKind{Kind: "Pod"}, schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	var recent []commander.Selector
+	for i := 0; i < selectorHistoryLimit+2; i++ {
+		recent = addRecentSelector(recent, commander.Selector{Label: fmt.Sprint("app=", i)})
+	}
+	recent = addRecentSelector(recent, commander.Selector{Label: "app=5"})
+	if err := store.Save(recentSelectorsKey(pods), recent); err != nil {
+		t.Fatal(err)
+	}
+	// Selectors are loaded after restart
+	store, err = state.NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := loadRecentSelectors(store, pods)
+	if len(loaded) != selectorHistoryLimit || loaded[0].Label != "app=5" || loaded[1].Label != "app=11" {
+		t.Errorf("unexpected recent selectors %v", loaded)
+	}
+	if loaded := loadRecentSelectors(store, deployments); len(loaded) != 0 {
+		t.Errorf("selectors of another kind are loaded: %v", loaded)
+	}
+}
@@ -0,0 +1,326 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type columnKind uint8
+
+const (
+	// Values are detected one by one: numbers, durations, quantities and then strings
+	kindAuto columnKind = iota
+	kindNumber
+	kindDuration
+	kindQuantity
+	kindDate
+)
+
+// Columns which hold human readable durations like "5d3h"
+var durationColumns = map[string]bool{
+	"Age":        true,
+	"Duration":   true,
+	"Last Seen":  true,
+	"First Seen": true,
+}
+
+func columnKindOf(name string, definition *metav1.TableColumnDefinition) columnKind {
+	if definition != nil {
+		switch definition.Type {
+		case "integer", "number":
+			return kindNumber
+		case "date":
+			return kindDate
+		}
+	}
+	if durationColumns[name] {
+		return kindDuration
+	}
+	return kindAuto
+}
+
+// sortKey is a value parsed for comparison. Values which couldn't be parsed as numbers are compared as strings
+// and go after numeric ones
+type sortKey struct {
+	numeric bool
+	num     float64
+	str     string
+}
+
+func newSortKey(value string, kind columnKind) sortKey {
+	switch kind {
+	case kindNumber:
+		if num, err := strconv.ParseFloat(value, 64); err == nil {
+			return sortKey{numeric: true, num: num}
+		}
+	case kindDuration:
+		if d, ok := parseHumanDuration(value); ok {
+			return sortKey{numeric: true, num: d.Seconds()}
+		}
+	case kindQuantity:
+		if q, err := resource.ParseQuantity(value); err == nil {
+			return sortKey{numeric: true, num: float64(q.MilliValue()) / 1000}
+		}
+	case kindDate:
+		// Dates are compared as ages, so ascending order always starts from the most recent
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return sortKey{numeric: true, num: time.Since(t).Seconds()}
+		}
+		if d, ok := parseHumanDuration(value); ok {
+			return sortKey{numeric: true, num: d.Seconds()}
+		}
+	default:
+		if num, err := strconv.ParseFloat(value, 64); err == nil {
+			return sortKey{numeric: true, num: num}
+		}
+		if d, ok := parseHumanDuration(value); ok {
+			return sortKey{numeric: true, num: d.Seconds()}
+		}
+		if q, err := resource.ParseQuantity(value); err == nil {
+			return sortKey{numeric: true, num: float64(q.MilliValue()) / 1000}
+		}
+	}
+	return sortKey{str: value}
+}
+
+// detectKind tells durations from quantities. Values like "5m" are both, so kind of a column is defined
+// by the first value which is only one of them
+func detectKind(value string) columnKind {
+	_, isDuration := parseHumanDuration(value)
+	_, err := resource.ParseQuantity(value)
+	switch isQuantity := err == nil; {
+	case isDuration && !isQuantity:
+		return kindDuration
+	case isQuantity && !isDuration:
+		return kindQuantity
+	}
+	return kindAuto
+}
+
+func compareKeys(a, b sortKey) int {
+	switch {
+	case a.numeric && b.numeric:
+		switch {
+		case a.num < b.num:
+			return -1
+		case a.num > b.num:
+			return 1
+		}
+		return 0
+	case a.numeric:
+		return -1
+	case b.numeric:
+		return 1
+	}
+	return strings.Compare(a.str, b.str)
+}
+
+var durationUnits = map[byte]time.Duration{
+	'y': time.Hour * 24 * 365,
+	'd': time.Hour * 24,
+	'h': time.Hour,
+	'm': time.Minute,
+	's': time.Second,
+}
+
+// parseHumanDuration parses durations as they are rendered by kubectl: 45s, 5m, 3h20m, 5d3h, 2y10d
+func parseHumanDuration(str string) (time.Duration, bool) {
+	if str == "" {
+		return 0, false
+	}
+	var (
+		total  time.Duration
+		number = -1
+	)
+	for i := 0; i < len(str); i++ {
+		ch := str[i]
+		if ch >= '0' && ch <= '9' {
+			if number == -1 {
+				number = 0
+			}
+			number = number*10 + int(ch-'0')
+			continue
+		}
+		unit, ok := durationUnits[ch]
+		if !ok || number == -1 {
+			return 0, false
+		}
+		total += time.Duration(number) * unit
+		number = -1
+	}
+	if number != -1 {
+		return 0, false
+	}
+	return total, true
+}
+
+// CycleSort switches sorting to the next state: ascending and then descending order of every column
+// one by one, and finally back to the original order
+func (lt *ListTable) CycleSort() {
+	switch {
+	case lt.sortCol == -1:
+		lt.SetSort(0, false)
+	case !lt.sortDesc:
+		lt.SetSort(lt.sortCol, true)
+	case lt.sortCol+1 < len(lt.columns):
+		lt.SetSort(lt.sortCol+1, false)
+	default:
+		lt.SetSort(-1, false)
+	}
+}
+
+// SetSort sorts rows by the column. Negative column restores the original order
+func (lt *ListTable) SetSort(column int, desc bool) {
+	if column >= len(lt.columns) {
+		return
+	}
+	name := ""
+	if column >= 0 {
+		name = lt.columns[column]
+	}
+	lt.SortBy(name, desc)
+}
+
+// Sort returns name of the column rows are sorted by. Empty name means the original order
+func (lt *ListTable) Sort() (string, bool) {
+	return lt.sortBy, lt.sortDesc
+}
+
+// SortBy sorts rows by the column name. Column which isn't there yet is picked up once columns are loaded
+func (lt *ListTable) SortBy(name string, desc bool) {
+	lt.sortBy = name
+	lt.sortDesc = desc
+	lt.setSortCol()
+	lt.rekey()
+	lt.resort()
+	lt.Render()
+	lt.reindexSelection()
+}
+
+// setSortCol finds the sort column after columns change
+func (lt *ListTable) setSortCol() {
+	lt.sortCol = -1
+	for i, name := range lt.columns {
+		if name == lt.sortBy && name != "" {
+			lt.sortCol = i
+			break
+		}
+	}
+	if lt.sortCol == -1 {
+		return
+	}
+	var definition *metav1.TableColumnDefinition
+	if colId := lt.colIds[lt.sortCol]; colId < len(lt.definitions) {
+		definition = &lt.definitions[colId]
+	}
+	lt.sortKind = columnKindOf(lt.sortBy, definition)
+}
+
+func (lt *ListTable) sortKey(n *rowNode) sortKey {
+	if lt.sortCol == -1 || lt.sortCol >= len(n.values) {
+		return sortKey{}
+	}
+	if ageRow, ok := n.row.(commander.RowWithAge); ok && lt.sortCol == lt.ageCol {
+		// Newer rows go first, just like rows with smaller age
+		return sortKey{numeric: true, num: -float64(ageRow.CreationTime().UnixNano())}
+	}
+	value := n.values[lt.sortCol]
+	if lt.sortKind == kindAuto {
+		if kind := detectKind(value); kind != kindAuto {
+			lt.sortKind = kind
+			lt.keysStale = true
+		}
+	}
+	return newSortKey(value, lt.sortKind)
+}
+
+// rekey computes sort keys of every row. When kind of the sort column gets detected meanwhile,
+// keys computed before are computed again
+func (lt *ListTable) rekey() {
+	lt.keysStale = false
+	lt.rows.Each(func(n *rowNode) {
+		n.key = lt.sortKey(n)
+	})
+	if lt.keysStale {
+		lt.rekey()
+	}
+}
+
+// ordered reports whether rows are kept in sort order, either by the sort column or by filter rank
+func (lt *ListTable) ordered() bool {
+	return lt.sortCol != -1 || lt.query.Ranked()
+}
+
+// compareNodes compares rows by filter rank and then by the sort column. Rows with equal values are ordered by id,
+// so the order stays stable as rows get updated
+func (lt *ListTable) compareNodes(a, b *rowNode) int {
+	if lt.query.Ranked() && a.rank != b.rank {
+		if a.rank > b.rank {
+			return -1
+		}
+		return 1
+	}
+	c := compareKeys(a.key, b.key)
+	if lt.sortDesc {
+		c = -c
+	}
+	if c == 0 {
+		c = strings.Compare(a.row.Id(), b.row.Id())
+	}
+	return c
+}
+
+func (lt *ListTable) sortedIndex(n *rowNode) int {
+	return lt.rows.Search(func(m *rowNode) bool {
+		return lt.compareNodes(m, n) < 0
+	})
+}
+
+// resort rebuilds the row store in sort order. Without sort column and ranking the original order is restored
+func (lt *ListTable) resort() {
+	nodes := make([]*rowNode, 0, lt.rows.Len())
+	lt.rows.Each(func(n *rowNode) {
+		if !lt.sorted {
+			// Rows are in the original order, so it is remembered before sorting
+			n.seq = len(nodes)
+			n.origin = nil
+		}
+		nodes = append(nodes, n)
+	})
+	if !lt.sorted {
+		lt.seq = len(nodes)
+	}
+	lt.sorted = lt.ordered()
+	if lt.sorted {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return lt.compareNodes(nodes[i], nodes[j]) < 0
+		})
+		lt.rows = newRowStore()
+		for i, n := range nodes {
+			lt.rows.Insert(i, n)
+		}
+		return
+	}
+	// Rows added while rows were sorted go last, and are placed where they were asked to in order of insertion
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if (a.origin == nil) != (b.origin == nil) {
+			return a.origin == nil
+		}
+		return a.seq < b.seq
+	})
+	lt.rows = newRowStore()
+	for _, n := range nodes {
+		index := lt.rows.Len()
+		if n.origin != nil {
+			index = lt.originIndex(n.row.Id(), *n.origin)
+			n.origin = nil
+		}
+		lt.rows.Insert(index, n)
+	}
+}
@@ -0,0 +1,80 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"testing"
+)
+
+func TestSortKeyOrder(t *testing.T) {
+	// Every value is expected to go before the next one
+	for _, c := range []struct {
+		kind   columnKind
+		values []string
+	}{
+		{kindAuto, []string{"30s", "5m", "1h", "2d"}},
+		{kindAuto, []string{"1", "2", "10"}},
+		{kindAuto, []string{"3h", "abc", "def"}},
+		{kindQuantity, []string{"500m", "1", "2Gi"}},
+		{kindDuration, []string{"59s", "1m", "1m1s"}},
+	} {
+		for i := 1; i < len(c.values); i++ {
+			a, b := newSortKey(c.values[i-1], c.kind), newSortKey(c.values[i], c.kind)
+			if compareKeys(a, b) >= 0 {
+				t.Errorf("%q is expected to go before %q", c.values[i-1], c.values[i])
+			}
+		}
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	for value, expected := range map[string]columnKind{
+		"30s":   kindDuration,
+		"1h30m": kindDuration,
+		"2Gi":   kindQuantity,
+		"1":     kindQuantity,
+		"5m":    kindAuto,
+		"abc":   kindAuto,
+	} {
+		if kind := detectKind(value); kind != expected {
+			t.Errorf("%q is detected as %d, expected %d", value, kind, expected)
+		}
+	}
+}
+
+// TestListTableSortsByDetectedKind checks that ambiguous values like "5m" are sorted as durations or quantities
+// depending on the other values in the column
+func TestListTableSortsByDetectedKind(t *testing.T) {
+	lt, _, l := newTestTable(t, WithHeaders|WithSort)
+	for _, c := range []struct {
+		values   []string
+		expected string
+	}{
+		{[]string{"5m", "30s", "1h"}, "[30s 5m 1h]"},
+		{[]string{"1", "2Gi", "500m"}, "[500m 1 2Gi]"},
+	} {
+		ops := []commander.Operation{
+			&commander.OpClear{},
+			&commander.OpSetColumns{Columns: []string{"Name"}},
+		}
+		for _, value := range c.values {
+			ops = append(ops, &commander.OpAdded{Row: row(value)})
+		}
+		var ids string
+		l.run(func() {
+			lt.Apply(ops)
+			lt.SortBy("Name", false)
+			ids = rowIds(lt.Rows())
+		})
+		if ids != c.expected {
+			t.Errorf("unexpected order %s, expected %s", ids, c.expected)
+		}
+		// Rows arrive after sorting is set
+		l.run(func() {
+			lt.Apply(ops)
+			ids = rowIds(lt.Rows())
+		})
+		if ids != c.expected {
+			t.Errorf("unexpected order %s of rows added to sorted table, expected %s", ids, c.expected)
+		}
+	}
+}
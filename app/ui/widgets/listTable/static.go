@@ -4,7 +4,12 @@ import "github.com/AnatolyRugalev/kube-commander/commander"
 
 func NewStaticListTable(columns []string, rows []commander.Row, format TableFormat) *ListTable {
 	lt := NewListTable(NewStaticRowProvider(columns, rows), format, nil)
-	lt.watch()
+	// Static table is populated right away, so there is no need to wait for UI event loop
+	for ops := range lt.rowProvider {
+		lt.apply(ops)
+	}
+	lt.Render()
+	lt.reindexSelection()
 	return lt
 }
 
@@ -0,0 +1,348 @@
+package listTable
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"math/rand"
+)
+
+// rowNode is a row stored in rowStore along with its rendered values
+type rowNode struct {
+	row     commander.Row
+	values  []string
+	widths  []int
+	key     sortKey
+	matched bool
+	// Rank of the match when filter ranks rows
+	rank int
+	// Position in the original order, or order of insertion for rows added while rows are sorted
+	seq int
+	// Where the row was asked to go when it was added while rows are sorted. It is placed there once
+	// the original order is restored
+	origin *origin
+
+	priority            uint32
+	left, right, parent *rowNode
+	// Number of nodes and matched nodes in the subtree
+	size    int
+	matches int
+}
+
+// origin is the requested position of a row in the original order: index, position by id or the end
+type origin struct {
+	index *int
+	byId  bool
+}
+
+// rowStore keeps rows in order and allows to insert, delete and look rows up by id or position in O(log n).
+// It is a treap with implicit keys, so position of a row is defined by the number of rows to the left of it.
+// Every subtree also counts rows matching the filter, so positions among matched rows are just as cheap
+type rowStore struct {
+	root  *rowNode
+	nodes map[string]*rowNode
+	rand  *rand.Rand
+}
+
+func newRowStore() *rowStore {
+	return &rowStore{
+		nodes: make(map[string]*rowNode),
+		rand:  rand.New(rand.NewSource(1)),
+	}
+}
+
+func size(n *rowNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func matches(n *rowNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.matches
+}
+
+// pull recalculates subtree counters and restores parent links of children
+func pull(n *rowNode) {
+	n.size = 1 + size(n.left) + size(n.right)
+	n.matches = matches(n.left) + matches(n.right)
+	if n.matched {
+		n.matches++
+	}
+	if n.left != nil {
+		n.left.parent = n
+	}
+	if n.right != nil {
+		n.right.parent = n
+	}
+}
+
+// split splits the tree into the first k nodes and the rest
+func split(n *rowNode, k int) (*rowNode, *rowNode) {
+	if n == nil {
+		return nil, nil
+	}
+	if size(n.left) >= k {
+		l, r := split(n.left, k)
+		n.left = r
+		pull(n)
+		if l != nil {
+			l.parent = nil
+		}
+		n.parent = nil
+		return l, n
+	}
+	l, r := split(n.right, k-size(n.left)-1)
+	n.right = l
+	pull(n)
+	if r != nil {
+		r.parent = nil
+	}
+	n.parent = nil
+	return n, r
+}
+
+func merge(a, b *rowNode) *rowNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = merge(a.right, b)
+		pull(a)
+		return a
+	}
+	b.left = merge(a, b.left)
+	pull(b)
+	return b
+}
+
+func (s *rowStore) Len() int {
+	return size(s.root)
+}
+
+// Matched returns the number of rows matching the filter
+func (s *rowStore) Matched() int {
+	return matches(s.root)
+}
+
+func (s *rowStore) Get(id string) *rowNode {
+	return s.nodes[id]
+}
+
+// Insert inserts a node at index
+func (s *rowStore) Insert(index int, n *rowNode) {
+	if index < 0 {
+		index = 0
+	}
+	if index > s.Len() {
+		index = s.Len()
+	}
+	n.priority = s.rand.Uint32()
+	n.left, n.right, n.parent = nil, nil, nil
+	pull(n)
+	l, r := split(s.root, index)
+	s.root = merge(merge(l, n), r)
+	s.root.parent = nil
+	s.nodes[n.row.Id()] = n
+}
+
+// SortedIndex returns the index of the first row with id greater than or equal to the given one,
+// assuming that rows are sorted by id
+func (s *rowStore) SortedIndex(id string) int {
+	return s.Search(func(n *rowNode) bool {
+		return n.row.Id() < id
+	})
+}
+
+// Search returns the index of the first node for which before returns false, assuming that
+// before returns true for all nodes up to some point and false afterwards
+func (s *rowStore) Search(before func(n *rowNode) bool) int {
+	index := 0
+	n := s.root
+	for n != nil {
+		if before(n) {
+			index += size(n.left) + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return index
+}
+
+func (s *rowStore) Delete(id string) *rowNode {
+	n, ok := s.nodes[id]
+	if !ok {
+		return nil
+	}
+	delete(s.nodes, id)
+	child := merge(n.left, n.right)
+	parent := n.parent
+	if child != nil {
+		child.parent = parent
+	}
+	if parent == nil {
+		s.root = child
+	} else if parent.left == n {
+		parent.left = child
+	} else {
+		parent.right = child
+	}
+	s.fixUp(parent)
+	n.left, n.right, n.parent = nil, nil, nil
+	return n
+}
+
+// SetMatched changes whether the node matches the filter
+func (s *rowStore) SetMatched(n *rowNode, matched bool) {
+	if n.matched == matched {
+		return
+	}
+	n.matched = matched
+	s.fixUp(n)
+}
+
+// fixUp recalculates counters from the node up to the root
+func (s *rowStore) fixUp(n *rowNode) {
+	for ; n != nil; n = n.parent {
+		pull(n)
+	}
+}
+
+// MatchedIndex returns the position of the node among matched rows
+func (s *rowStore) MatchedIndex(n *rowNode) int {
+	index := matches(n.left)
+	for ; n.parent != nil; n = n.parent {
+		if n.parent.right == n {
+			index += matches(n.parent.left)
+			if n.parent.matched {
+				index++
+			}
+		}
+	}
+	return index
+}
+
+// MatchedAt returns k-th matched node
+func (s *rowStore) MatchedAt(k int) *rowNode {
+	n := s.root
+	for n != nil {
+		left := matches(n.left)
+		if k < left {
+			n = n.left
+			continue
+		}
+		k -= left
+		if n.matched {
+			if k == 0 {
+				return n
+			}
+			k--
+		}
+		n = n.right
+	}
+	return nil
+}
+
+// Recount recalculates counters of the whole tree. It is cheaper than SetMatched when many nodes change
+func (s *rowStore) Recount() {
+	recount(s.root)
+}
+
+func recount(n *rowNode) {
+	if n == nil {
+		return
+	}
+	recount(n.left)
+	recount(n.right)
+	pull(n)
+}
+
+// next returns the next node in order
+func next(n *rowNode) *rowNode {
+	if n.right != nil {
+		n = n.right
+		for n.left != nil {
+			n = n.left
+		}
+		return n
+	}
+	for n.parent != nil && n.parent.right == n {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// Each calls f for every node in order
+func (s *rowStore) Each(f func(n *rowNode)) {
+	if s.root == nil {
+		return
+	}
+	n := s.root
+	for n.left != nil {
+		n = n.left
+	}
+	for ; n != nil; n = next(n) {
+		f(n)
+	}
+}
+
+// widthTracker keeps track of the widest value of every column. Widths are counted,
+// so removing a value doesn't require measuring all other values again
+type widthTracker struct {
+	counts []map[int]int
+	max    []int
+}
+
+func newWidthTracker(columns int) *widthTracker {
+	t := &widthTracker{
+		counts: make([]map[int]int, columns),
+		max:    make([]int, columns),
+	}
+	for i := range t.counts {
+		t.counts[i] = make(map[int]int)
+	}
+	return t
+}
+
+func (t *widthTracker) Add(widths []int) {
+	for i, w := range widths {
+		if i >= len(t.counts) {
+			break
+		}
+		t.counts[i][w]++
+		if w > t.max[i] {
+			t.max[i] = w
+		}
+	}
+}
+
+func (t *widthTracker) Remove(widths []int) {
+	for i, w := range widths {
+		if i >= len(t.counts) {
+			break
+		}
+		t.counts[i][w]--
+		if t.counts[i][w] > 0 {
+			continue
+		}
+		delete(t.counts[i], w)
+		if w == t.max[i] {
+			t.max[i] = 0
+			for width := range t.counts[i] {
+				if width > t.max[i] {
+					t.max[i] = width
+				}
+			}
+		}
+	}
+}
+
+// Max returns the widest value of the column
+func (t *widthTracker) Max(column int) int {
+	return t.max[column]
+}
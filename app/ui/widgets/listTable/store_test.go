@@ -0,0 +1,250 @@
+package listTable
+
+import (
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+	"math/rand"
+	"testing"
+)
+
+func newNode(id string) *rowNode {
+	return &rowNode{row: commander.NewSimpleRow(id, []string{id}, true), matched: true}
+}
+
+func storeIds(s *rowStore) []string {
+	var ids []string
+	s.Each(func(n *rowNode) {
+		ids = append(ids, n.row.Id())
+	})
+	return ids
+}
+
+// checkStore verifies counters and parent links of every node
+func checkStore(t *testing.T, s *rowStore) {
+	t.Helper()
+	var check func(n, parent *rowNode) (int, int)
+	check = func(n, parent *rowNode) (int, int) {
+		if n == nil {
+			return 0, 0
+		}
+		if n.parent != parent {
+			t.Fatalf("node %s has wrong parent", n.row.Id())
+		}
+		ls, lm := check(n.left, n)
+		rs, rm := check(n.right, n)
+		m := lm + rm
+		if n.matched {
+			m++
+		}
+		if n.size != ls+rs+1 || n.matches != m {
+			t.Fatalf("node %s has size %d and matches %d, expected %d and %d", n.row.Id(), n.size, n.matches, ls+rs+1, m)
+		}
+		return n.size, n.matches
+	}
+	size, _ := check(s.root, nil)
+	if size != len(s.nodes) {
+		t.Fatalf("tree has %d nodes, index has %d", size, len(s.nodes))
+	}
+}
+
+func TestRowStoreInsertDelete(t *testing.T) {
+	s := newRowStore()
+	s.Insert(0, newNode("b"))
+	s.Insert(0, newNode("a"))
+	s.Insert(2, newNode("d"))
+	s.Insert(2, newNode("c"))
+	// Out of range indexes are clamped
+	s.Insert(100, newNode("e"))
+	s.Insert(-1, newNode("0"))
+	checkStore(t, s)
+	if ids := fmt.Sprint(storeIds(s)); ids != "[0 a b c d e]" {
+		t.Fatalf("unexpected order %s", ids)
+	}
+	if n := s.Delete("c"); n == nil || n.row.Id() != "c" {
+		t.Fatalf("deleted node isn't returned")
+	}
+	if s.Delete("c") != nil {
+		t.Fatalf("node is deleted twice")
+	}
+	checkStore(t, s)
+	if ids := fmt.Sprint(storeIds(s)); ids != "[0 a b d e]" {
+		t.Fatalf("unexpected order %s", ids)
+	}
+	if s.Get("d") == nil || s.Get("c") != nil {
+		t.Fatalf("index isn't updated")
+	}
+	if s.Len() != 5 {
+		t.Fatalf("unexpected length %d", s.Len())
+	}
+}
+
+func TestRowStoreSortedIndex(t *testing.T) {
+	s := newRowStore()
+	for _, id := range []string{"d", "b", "a", "e", "c"} {
+		s.Insert(s.SortedIndex(id), newNode(id))
+	}
+	checkStore(t, s)
+	if ids := fmt.Sprint(storeIds(s)); ids != "[a b c d e]" {
+		t.Fatalf("unexpected order %s", ids)
+	}
+	if index := s.SortedIndex("bb"); index != 2 {
+		t.Fatalf("unexpected index %d", index)
+	}
+}
+
+func TestRowStoreMatched(t *testing.T) {
+	s := newRowStore()
+	for i := 0; i < 10; i++ {
+		s.Insert(i, newNode(fmt.Sprint(i)))
+	}
+	for i := 0; i < 10; i += 2 {
+		s.SetMatched(s.Get(fmt.Sprint(i)), false)
+	}
+	checkStore(t, s)
+	if s.Matched() != 5 {
+		t.Fatalf("unexpected number of matched rows %d", s.Matched())
+	}
+	for k := 0; k < 5; k++ {
+		n := s.MatchedAt(k)
+		if n == nil || n.row.Id() != fmt.Sprint(k*2+1) {
+			t.Fatalf("unexpected matched row %d: %v", k, n)
+		}
+		if index := s.MatchedIndex(n); index != k {
+			t.Fatalf("unexpected index %d of matched row %d", index, k)
+		}
+	}
+	if s.MatchedAt(5) != nil {
+		t.Fatalf("matched row out of range")
+	}
+	// Recount picks up flags changed without SetMatched
+	s.Each(func(n *rowNode) {
+		n.matched = true
+	})
+	s.Recount()
+	checkStore(t, s)
+	if s.Matched() != 10 {
+		t.Fatalf("unexpected number of matched rows %d after recount", s.Matched())
+	}
+}
+
+// TestRowStoreRandom compares the store with a slice under random operations
+func TestRowStoreRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	s := newRowStore()
+	var ids []string
+	for i := 0; i < 5000; i++ {
+		switch op := r.Intn(3); {
+		case op < 2 || len(ids) == 0:
+			id := fmt.Sprint("row-", i)
+			index := r.Intn(len(ids) + 1)
+			s.Insert(index, newNode(id))
+			ids = append(ids[:index], append([]string{id}, ids[index:]...)...)
+		default:
+			index := r.Intn(len(ids))
+			s.Delete(ids[index])
+			ids = append(ids[:index], ids[index+1:]...)
+		}
+		if i%500 == 0 {
+			checkStore(t, s)
+		}
+	}
+	checkStore(t, s)
+	if fmt.Sprint(storeIds(s)) != fmt.Sprint(ids) {
+		t.Fatalf("store order differs from the expected one")
+	}
+}
+
+func TestWidthTracker(t *testing.T) {
+	w := newWidthTracker(2)
+	w.Add([]int{3, 10})
+	w.Add([]int{5, 10})
+	w.Add([]int{5, 2})
+	if w.Max(0) != 5 || w.Max(1) != 10 {
+		t.Fatalf("unexpected widths %d %d", w.Max(0), w.Max(1))
+	}
+	// The widest value is still there in another row
+	w.Remove([]int{5, 10})
+	if w.Max(0) != 5 || w.Max(1) != 10 {
+		t.Fatalf("unexpected widths %d %d after removing a duplicate", w.Max(0), w.Max(1))
+	}
+	w.Remove([]int{5, 10})
+	if w.Max(0) != 3 || w.Max(1) != 2 {
+		t.Fatalf("unexpected widths %d %d after removing the widest", w.Max(0), w.Max(1))
+	}
+	// Extra columns are ignored
+	w.Add([]int{1, 1, 100})
+	w.Remove([]int{3, 2})
+	w.Remove([]int{1, 1, 100})
+	if w.Max(0) != 0 || w.Max(1) != 0 {
+		t.Fatalf("unexpected widths %d %d of empty tracker", w.Max(0), w.Max(1))
+	}
+}
+
+const benchmarkRows = 50000
+
+func benchmarkStore() (*rowStore, []string) {
+	s := newRowStore()
+	ids := make([]string, benchmarkRows)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("pod-%06d", i)
+		s.Insert(i, newNode(ids[i]))
+	}
+	return s, ids
+}
+
+func BenchmarkRowStoreInsert50k(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchmarkStore()
+	}
+}
+
+// BenchmarkRowStoreUpdate50k replaces a row of a 50k rows table at its sorted position,
+// like a modification of a watched object does
+func BenchmarkRowStoreUpdate50k(b *testing.B) {
+	s, ids := benchmarkStore()
+	r := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := ids[r.Intn(len(ids))]
+		s.Delete(id)
+		s.Insert(s.SortedIndex(id), newNode(id))
+	}
+}
+
+// BenchmarkRowStoreFrame50k applies a frame of 300 updates to a 50k rows table and looks up
+// a screen of visible rows, which is the work done for every redraw
+func BenchmarkRowStoreFrame50k(b *testing.B) {
+	s, ids := benchmarkStore()
+	widths := newWidthTracker(1)
+	s.Each(func(n *rowNode) {
+		n.widths = []int{len(n.row.Id())}
+		widths.Add(n.widths)
+	})
+	r := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 300; j++ {
+			id := ids[r.Intn(len(ids))]
+			old := s.Delete(id)
+			widths.Remove(old.widths)
+			n := newNode(id)
+			n.widths = old.widths
+			s.Insert(s.SortedIndex(id), n)
+			widths.Add(n.widths)
+			s.SetMatched(n, r.Intn(2) == 0)
+		}
+		top := r.Intn(s.Matched() - 50)
+		for k := top; k < top+50; k++ {
+			s.MatchedAt(k)
+		}
+	}
+}
+
+func BenchmarkRowStoreMatchedAt50k(b *testing.B) {
+	s, _ := benchmarkStore()
+	r := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.MatchedAt(r.Intn(benchmarkRows))
+	}
+}
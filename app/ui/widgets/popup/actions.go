@@ -0,0 +1,11 @@
+package popup
+
+import "github.com/AnatolyRugalev/kube-commander/app/actions"
+
+// Actions shared by popups
+var (
+	ActApply  = actions.Register("popup.apply", "Apply or pick selected item", actions.Popup, "Enter")
+	ActRemove = actions.Register("popup.remove", "Remove selected item", actions.Popup, "Delete")
+	ActNext   = actions.Register("popup.next", "Next input or option", actions.Popup, "Tab")
+	ActPrev   = actions.Register("popup.prev", "Previous input or option", actions.Popup, "Backtab")
+)
@@ -0,0 +1,117 @@
+package workspace
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+)
+
+const historyLimit = 50
+
+var (
+	actHistoryBack    = actions.Register("global.historyBack", "Back to the previous view", actions.Global, "Alt+Left", "[")
+	actHistoryForward = actions.Register("global.historyForward", "Forward to the next view", actions.Global, "Alt+Right", "]")
+)
+
+// navigable is implemented by resource lists, so their state is kept in history
+type navigable interface {
+	Selector() commander.Selector
+	SetSelector(selector commander.Selector)
+	Position() listTable.Position
+	SetPosition(position listTable.Position)
+}
+
+// view is what the workspace shows: widget of the resource kind, namespace, and state of the list
+type view struct {
+	itemId    string
+	widget    commander.Widget
+	namespace string
+	selector  commander.Selector
+	position  listTable.Position
+}
+
+// same reports whether both views show the same list, regardless of selection and scroll
+func (v view) same(other view) bool {
+	return v.widget == other.widget && v.namespace == other.namespace && v.selector == other.selector
+}
+
+// history is a list of views like in browsers. Views after the current one are there after going back
+type history struct {
+	views   []view
+	current int
+}
+
+// push adds the view after the current one, dropping views which were ahead
+func (h *history) push(v view) {
+	if h.views[h.current].same(v) {
+		h.views[h.current] = v
+		return
+	}
+	h.views = append(h.views[:h.current+1], v)
+	if len(h.views) > historyLimit {
+		h.views = h.views[len(h.views)-historyLimit:]
+	}
+	h.current = len(h.views) - 1
+}
+
+func (w *workspace) snapshot() view {
+	v := view{
+		itemId:    w.selectedWidgetId,
+		widget:    w.widget,
+		namespace: w.namespace,
+	}
+	if nav, ok := w.widget.(navigable); ok {
+		v.selector = nav.Selector()
+		v.position = nav.Position()
+	}
+	return v
+}
+
+// navigate changes the view and remembers it in history. Changes made by the change itself make a single entry
+func (w *workspace) navigate(change func()) {
+	if w.navigating {
+		change()
+		return
+	}
+	w.navigating = true
+	w.history.views[w.history.current] = w.snapshot()
+	change()
+	w.navigating = false
+	w.history.push(w.snapshot())
+}
+
+// goHistory moves back or forward in history and restores the view
+func (w *workspace) goHistory(delta int) {
+	index := w.history.current + delta
+	if index < 0 || index >= len(w.history.views) {
+		return
+	}
+	w.history.views[w.history.current] = w.snapshot()
+	w.history.current = index
+	w.restore(w.history.views[index])
+}
+
+// restore shows the view exactly as it was
+func (w *workspace) restore(v view) {
+	namespaceChanged := v.namespace != w.namespace
+	w.namespace = v.namespace
+	nav, ok := v.widget.(navigable)
+	if ok && nav.Selector() != v.selector {
+		// Visible list is restarted by the selector, and the new namespace is picked up as well
+		nav.SetSelector(v.selector)
+	} else if namespaceChanged && v.widget == w.widget {
+		w.widget.OnHide()
+		w.widget.OnShow()
+	}
+	w.selectedWidgetId = v.itemId
+	w.showWidget(v.widget)
+	if w.menu.RowById(v.itemId) != nil {
+		w.menu.SelectItem(v.itemId)
+	}
+	w.menu.Render()
+	if ok {
+		nav.SetPosition(v.position)
+	}
+	w.focus.Focus(w.widget)
+	w.UpdateScreen()
+}
@@ -0,0 +1,97 @@
+package workspace
+
+import (
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/commander"
+)
+
+const (
+	sessionsKey    = "sessions"
+	lastContextKey = "context"
+)
+
+var (
+	// RestoreSession shows the view which was open when the app quit the last time in the same context
+	RestoreSession = true
+	// RestoreNamespace restores namespace of the session as well. It is off when namespace is set explicitly
+	RestoreNamespace = true
+)
+
+// session is the view the app quit with. Sessions are kept for every context
+type session struct {
+	Namespace string `json:"namespace"`
+	// Menu item id of the resource kind, empty for help
+	Item     string           `json:"item,omitempty"`
+	Filter   listTable.Filter `json:"filter,omitempty"`
+	SortBy   string           `json:"sortBy,omitempty"`
+	SortDesc bool             `json:"sortDesc,omitempty"`
+	RowId    string           `json:"rowId,omitempty"`
+}
+
+// sessionList is implemented by resource lists, so their filter, sort and selection are kept in the session
+type sessionList interface {
+	Filter() listTable.Filter
+	SetFilter(filter listTable.Filter)
+	Sort() (string, bool)
+	SortBy(name string, desc bool)
+	Position() listTable.Position
+	SetPosition(position listTable.Position)
+}
+
+func loadSessions(store commander.StateStore) map[string]session {
+	sessions := make(map[string]session)
+	store.Load(sessionsKey, &sessions)
+	return sessions
+}
+
+// LastContext returns context the app quit with the last time
+func LastContext(store commander.StateStore) string {
+	var context string
+	store.Load(lastContextKey, &context)
+	return context
+}
+
+// SaveSession remembers the current view for the current context
+func (w *workspace) SaveSession() error {
+	context := w.container.Config().Context()
+	s := session{
+		Namespace: w.namespace,
+		Item:      w.selectedWidgetId,
+	}
+	if list, ok := w.widget.(sessionList); ok {
+		s.Filter = list.Filter()
+		s.SortBy, s.SortDesc = list.Sort()
+		s.RowId = list.Position().RowId
+	}
+	store := w.StateStore()
+	sessions := loadSessions(store)
+	sessions[context] = s
+	if err := store.Save(sessionsKey, sessions); err != nil {
+		return err
+	}
+	return store.Save(lastContextKey, context)
+}
+
+// restoreSession opens the view of the last session. It is called once menu items are loaded
+func (w *workspace) restoreSession() {
+	s, ok := loadSessions(w.StateStore())[w.container.Config().Context()]
+	if !ok {
+		return
+	}
+	if RestoreNamespace && s.Namespace != w.namespace {
+		w.namespace = s.Namespace
+		w.widget.OnHide()
+		w.widget.OnShow()
+		w.menu.Render()
+	}
+	if s.Item != "" && w.menu.OpenItem(s.Item) {
+		if list, ok := w.widget.(sessionList); ok {
+			list.SetFilter(s.Filter)
+			list.SortBy(s.SortBy, s.SortDesc)
+			if s.RowId != "" {
+				list.SetPosition(listTable.Position{RowId: s.RowId})
+			}
+		}
+	}
+	w.UpdateScreen()
+}
@@ -1,10 +1,17 @@
 package workspace
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/client"
 	"github.com/AnatolyRugalev/kube-commander/app/focus"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/border"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/command"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/help"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/palette"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/resourceMenu"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/resources/namespace"
 	"github.com/AnatolyRugalev/kube-commander/app/ui/theme"
@@ -13,15 +20,29 @@ import (
 	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+var (
+	actNamespace   = actions.Register("global.namespace", "Switch namespace", actions.Global, "Ctrl+N", "F2")
+	actPods        = actions.Register("global.pods", "Go to pods", actions.Global, "Ctrl+P")
+	actDeployments = actions.Register("global.deployments", "Go to deployments", actions.Global, "Ctrl+D")
+	actIngresses   = actions.Register("global.ingresses", "Go to ingresses", actions.Global, "Ctrl+I")
+	actHelp        = actions.Register("global.help", "Show help", actions.Global, "?")
+	actPalette     = actions.Register("global.palette", "Search and run actions", actions.Global, "Ctrl+K")
+	actCommand     = actions.Register("global.command", "Command line, like :deploy, :svc -n kube-system, :ns default or :ctx prod", actions.Global, ":")
+)
+
 type workspace struct {
 	*views.BoxLayout
 	focus.Focusable
 
-	container commander.Container
+	container commander.App
 	focus     commander.FocusManager
+	view      views.View
+	clicks    mouse.Clicks
 
 	popup  commander.Popup
 	menu   *resourceMenu.ResourceMenu
@@ -31,12 +52,26 @@ type workspace struct {
 	namespaceResource *commander.Resource
 
 	selectedWidgetId string
+	history          history
+	navigating       bool
 }
 
 func (w *workspace) ResourceProvider() commander.ResourceProvider {
 	return w.container.ResourceProvider()
 }
 
+func (w *workspace) ResourceCache() commander.ResourceCache {
+	return w.container.ResourceCache()
+}
+
+func (w *workspace) StateStore() commander.StateStore {
+	return w.container.StateStore()
+}
+
+func (w *workspace) SettingsStore() commander.SettingsStore {
+	return w.container.SettingsStore()
+}
+
 func (w *workspace) CommandBuilder() commander.CommandBuilder {
 	return w.container.CommandBuilder()
 }
@@ -55,14 +90,16 @@ func (w *workspace) CurrentNamespace() string {
 }
 
 func (w *workspace) SwitchNamespace(namespace string) {
-	w.namespace = namespace
-	w.widget.OnHide()
-	w.widget.OnShow()
+	w.navigate(func() {
+		w.namespace = namespace
+		w.widget.OnHide()
+		w.widget.OnShow()
+	})
 	w.menu.Render()
 	w.UpdateScreen()
 }
 
-func NewWorkspace(container commander.Container, namespace string) *workspace {
+func NewWorkspace(container commander.App, namespace string) *workspace {
 	return &workspace{
 		BoxLayout: views.NewBoxLayout(views.Horizontal),
 		container: container,
@@ -95,6 +132,12 @@ func (w *workspace) UpdateScreen() {
 	}
 }
 
+func (w *workspace) PostFunc(f func()) {
+	if screen := w.container.Screen(); screen != nil {
+		screen.PostFunc(f)
+	}
+}
+
 func (w *workspace) ScreenUpdater() commander.ScreenUpdater {
 	return w
 }
@@ -118,36 +161,209 @@ func (w workspace) Resize() {
 	}
 }
 
+func (w *workspace) SetView(view views.View) {
+	w.view = view
+	w.BoxLayout.SetView(view)
+}
+
 func (w *workspace) HandleEvent(e tcell.Event) bool {
 	if w.Status().HandleEvent(e) {
 		return true
 	}
+	if ev, ok := e.(*tcell.EventMouse); ok {
+		return w.handleMouse(ev)
+	}
 	if w.focus.HandleEvent(e, w.popup == nil) {
 		return true
 	}
 	if w.popup != nil {
 		return false
 	}
-	switch ev := e.(type) {
-	case *tcell.EventKey:
-		switch ev.Key() {
-		case tcell.KeyCtrlN, tcell.KeyF2:
-			namespace.PickNamespace(w, w.namespaceResource, w.SwitchNamespace)
-		case tcell.KeyCtrlP:
-			w.focus.Focus(w.menu)
-			w.menu.SelectItem("Pods")
-		case tcell.KeyCtrlD:
-			w.focus.Focus(w.menu)
-			w.menu.SelectItem("Deployments")
-		case tcell.KeyCtrlI:
-			w.focus.Focus(w.menu)
-			w.menu.SelectItem("Ingresses")
-		default:
-			if ev.Rune() == '?' {
-				help.ShowHelpPopup(w)
-				return true
+	if ev, ok := e.(*tcell.EventKey); ok {
+		return actions.HandleKey(ev, w.HandleAction, actions.Global)
+	}
+	return false
+}
+
+// HandleAction runs global actions of the workspace
+func (w *workspace) HandleAction(a *actions.Action) bool {
+	switch a {
+	case actNamespace:
+		namespace.PickNamespace(w, w.namespaceResource, w.SwitchNamespace)
+	case actPods:
+		w.focus.Focus(w.menu)
+		w.menu.SelectItem("Pods")
+	case actDeployments:
+		w.focus.Focus(w.menu)
+		w.menu.SelectItem("Deployments")
+	case actIngresses:
+		w.focus.Focus(w.menu)
+		w.menu.SelectItem("Ingresses")
+	case actHelp:
+		help.ShowHelpPopup(w, actions.ScopesOf(w.focus.Current()))
+	case actPalette:
+		w.showPalette()
+	case actCommand:
+		w.showCommandLine()
+	case actHistoryBack:
+		w.goHistory(-1)
+	case actHistoryForward:
+		w.goHistory(1)
+	default:
+		return false
+	}
+	return true
+}
+
+// showPalette lists actions of the focused widget. Picked action is run by the widget, or by the workspace,
+// focus manager and screen for global ones
+func (w *workspace) showPalette() {
+	target := w.focus.Current()
+	var available []*actions.Action
+	for _, a := range actions.Available(target, actions.ScopesOf(target)) {
+		// Theme editor keys work only while editing
+		if a.Scope != actions.Theme && a != actPalette {
+			available = append(available, a)
+		}
+	}
+	palette.Show(w, available, func(a *actions.Action) {
+		w.focus.Blur()
+		for _, h := range []interface{}{target, w, w.focus, w.container.Screen()} {
+			if handler, ok := h.(actions.Handler); ok && handler.HandleAction(a) {
+				break
 			}
 		}
+		w.UpdateScreen()
+	})
+}
+
+// showCommandLine shows command line. Resources, contexts and namespaces to complete are loaded in background
+func (w *workspace) showCommandLine() {
+	completer := &command.Completer{Resources: client.CoreResources()}
+	go func() {
+		resources, err := w.ResourceProvider().Resources()
+		contexts, _ := w.container.Config().Contexts()
+		namespaces := w.namespaces()
+		w.PostFunc(func() {
+			if err == nil {
+				completer.Resources = resources
+			}
+			completer.Contexts = contexts
+			completer.Namespaces = namespaces
+			w.UpdateScreen()
+		})
+	}()
+	command.Show(w, completer, func(cmd command.Command) error {
+		return w.runCommand(completer, cmd)
+	})
+}
+
+func (w *workspace) namespaces() []string {
+	var list v1.NamespaceList
+	err := w.Client().List(context.Background(), w.namespaceResource, "", metav1.ListOptions{}, &list)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+	return names
+}
+
+// runCommand runs command line. Errors are returned before the command line is closed, so they could be fixed
+func (w *workspace) runCommand(completer *command.Completer, cmd command.Command) error {
+	namespace := w.namespace
+	switch {
+	case cmd.AllNamespaces:
+		namespace = ""
+	case cmd.Namespace != "":
+		namespace = cmd.Namespace
+	}
+	switch cmd.Name {
+	case command.Context:
+		if cmd.Arg == "" {
+			return errors.New("ctx needs a context name")
+		}
+		contexts, err := w.container.Config().Contexts()
+		if err != nil {
+			return err
+		}
+		if !containsString(contexts, cmd.Arg) {
+			return fmt.Errorf("unknown context %s", cmd.Arg)
+		}
+		w.focus.Blur()
+		w.container.SwitchContext(cmd.Arg, cmd.Namespace)
+		return nil
+	case command.Namespace:
+		if cmd.Arg != "" {
+			w.focus.Blur()
+			w.SwitchNamespace(cmd.Arg)
+			return nil
+		}
+	}
+	name := cmd.Name
+	switch {
+	case cmd.Name == command.CRD && cmd.Arg != "":
+		name = cmd.Arg
+	case cmd.Arg != "":
+		return fmt.Errorf("%s doesn't take arguments", cmd.Name)
+	case cmd.Name == command.CRD:
+		name = "customresourcedefinitions"
+	}
+	res, err := command.Resolve(completer.Resources, name)
+	if err != nil {
+		return err
+	}
+	w.focus.Blur()
+	w.navigate(func() {
+		if namespace != w.namespace {
+			w.SwitchNamespace(namespace)
+		}
+		if !w.menu.Open(res.Gk) {
+			w.Status().Warning(res.Gk.Kind + " isn't loaded yet")
+		}
+	})
+	return nil
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// handleMouse passes clicks and wheel motion to the widget under the mouse. Clicked widget gets focus,
+// and click outside of a popup closes it
+func (w *workspace) handleMouse(ev *tcell.EventMouse) bool {
+	ev, ok := w.clicks.Filter(ev)
+	if !ok {
+		return false
+	}
+	if w.popup != nil {
+		// Popup is positioned on the screen, so it gets the event in screen coordinates
+		if !w.popup.HandleEvent(ev) && mouse.IsClick(ev) {
+			w.focus.Blur()
+		}
+		return true
+	}
+	local, ok := mouse.Local(ev, w.view)
+	if !ok {
+		return false
+	}
+	for _, widget := range []commander.Widget{w.menu, w.widget} {
+		current := w.focus.Current()
+		if !widget.HandleEvent(local) {
+			continue
+		}
+		// Widget could move focus on its own, e.g. when menu item is opened
+		if mouse.IsClick(local) && w.focus.Current() == current {
+			w.focus.Focus(widget)
+		}
+		return true
 	}
 	return false
 }
@@ -166,11 +382,21 @@ func (w *workspace) Init() error {
 	resMenu.SetStyler(w.styler)
 	w.menu = resMenu
 	w.menu.OnShow()
-	w.widget = help.NewHelpWidget()
+	w.widget = help.NewHelpWidget(actions.Scopes())
 	w.BoxLayout.AddWidget(w.menu, 0.0)
 	w.BoxLayout.AddWidget(border.NewVerticalLine(theme.Default), 0.0)
 	w.BoxLayout.AddWidget(w.widget, 1.0)
 	w.focus = focus.NewFocusManager(w.menu)
+	w.history = history{views: []view{w.snapshot()}}
+	if RestoreSession {
+		restored := false
+		w.menu.BindOnInitFinish(func() {
+			if !restored {
+				restored = true
+				w.restoreSession()
+			}
+		})
+	}
 
 	return nil
 }
@@ -186,15 +412,34 @@ func (w *workspace) styler(list commander.ListView, row commander.Row) tcell.Sty
 	return style
 }
 
-func (w *workspace) onMenuSelect(_ string, widget commander.Widget) bool {
-	if widget != w.widget {
-		w.widget.OnHide()
-		w.BoxLayout.RemoveWidget(w.widget)
-		w.widget = widget
-		w.BoxLayout.AddWidget(w.widget, 0.9)
-		w.widget.OnShow()
-	}
+func (w *workspace) onMenuSelect(itemId string, widget commander.Widget) bool {
+	w.navigate(func() {
+		w.selectedWidgetId = itemId
+		w.showWidget(widget)
+	})
 	w.focus.Focus(w.widget)
 
 	return true
 }
+
+// OnHide hides every widget, so they stop watching resources once the app stops
+func (w *workspace) OnHide() {
+	if w.popup != nil {
+		w.popup.OnHide()
+		w.popup = nil
+	}
+	w.widget.OnHide()
+	w.menu.OnHide()
+	w.Focusable.OnHide()
+}
+
+func (w *workspace) showWidget(widget commander.Widget) {
+	if widget == w.widget {
+		return
+	}
+	w.widget.OnHide()
+	w.BoxLayout.RemoveWidget(w.widget)
+	w.widget = widget
+	w.BoxLayout.AddWidget(w.widget, 0.9)
+	w.widget.OnShow()
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/AnatolyRugalev/kube-commander/app"
+	"github.com/AnatolyRugalev/kube-commander/app/actions"
 	"github.com/AnatolyRugalev/kube-commander/app/builder"
 	"github.com/AnatolyRugalev/kube-commander/app/client"
+	"github.com/AnatolyRugalev/kube-commander/app/config"
 	"github.com/AnatolyRugalev/kube-commander/app/executor"
+	"github.com/AnatolyRugalev/kube-commander/app/state"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/mouse"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/widgets/listTable"
+	"github.com/AnatolyRugalev/kube-commander/app/ui/workspace"
+	"github.com/AnatolyRugalev/kube-commander/commander"
 	"github.com/spf13/cobra"
 	cmd "k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
 	"os"
+	"strconv"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/azure"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -37,6 +46,10 @@ var cfg = struct {
 	context    string
 	namespace  string
 	klog       string
+	redrawRate int
+	config     string
+	noMouse    bool
+	noSession  bool
 }{}
 
 const (
@@ -46,6 +59,10 @@ const (
 	ContextEnv   = "KUBECONTEXT"
 	NamespaceEnv = "KUBENAMESPACE"
 	KLogEnv      = "KUBELOG"
+	RedrawEnv    = "KUBEREDRAWRATE"
+	ConfigEnv    = "KUBECOMMANDERCONFIG"
+	NoMouseEnv   = "KUBENOMOUSE"
+	NoSessionEnv = "KUBENOSESSION"
 )
 
 func main() {
@@ -63,6 +80,23 @@ func defaultEnv(name string, def string) string {
 	return val
 }
 
+// defaultEnvInt returns positive number from the env var. Invalid value is reported and the default is used instead
+func defaultEnvInt(name string, def int) int {
+	val := os.Getenv(name)
+	if val == "" {
+		return def
+	}
+	i, err := strconv.Atoi(val)
+	if err == nil && i <= 0 {
+		err = errors.New("value must be positive")
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "invalid %s=%s: %s, using %d\n", name, val, err.Error(), def)
+		return def
+	}
+	return i
+}
+
 func init() {
 	rootCmd.Flags().StringVarP(&cfg.kubectl, "kubectl", "k", defaultEnv(KubectlEnv, "kubectl"), "kubectl path override")
 	rootCmd.Flags().StringVarP(&cfg.editor, "editor", "e", defaultEnv(EditorEnv, "vi"), "Editor override")
@@ -71,6 +105,10 @@ func init() {
 	rootCmd.Flags().StringVarP(&cfg.context, "context", "c", defaultEnv(ContextEnv, ""), "Context name (default: current context)")
 	rootCmd.Flags().StringVarP(&cfg.namespace, "namespace", "n", defaultEnv(NamespaceEnv, ""), "Namespace name to start with (default: from context)")
 	rootCmd.Flags().StringVarP(&cfg.klog, "klog", "", defaultEnv(KLogEnv, ""), "Log file for Kubernetes logging library")
+	rootCmd.Flags().IntVarP(&cfg.redrawRate, "max-redraw-rate", "", defaultEnvInt(RedrawEnv, listTable.MaxRedrawRate), "Maximum number of list redraws per second")
+	rootCmd.Flags().StringVarP(&cfg.config, "config", "", defaultEnv(ConfigEnv, config.DefaultPath()), "Config file path")
+	rootCmd.Flags().BoolVarP(&cfg.noMouse, "no-mouse", "", defaultEnv(NoMouseEnv, "") != "", "Disable mouse capture, so terminal text selection works")
+	rootCmd.Flags().BoolVarP(&cfg.noSession, "no-session", "", defaultEnv(NoSessionEnv, "") != "", "Start on help instead of the view of the last run")
 	klog.InitFlags(logFlags)
 	_ = logFlags.Set("logtostderr", "false")
 	_ = logFlags.Set("alsologtostderr", "false")
@@ -80,12 +118,62 @@ func init() {
 func run(_ *cobra.Command, _ []string) error {
 	_ = logFlags.Set("log_file", cfg.klog)
 	_ = os.Setenv(cmd.RecommendedConfigPathEnvVar, cfg.kubeconfig)
-	conf := client.NewDefaultConfig(cfg.kubeconfig, cfg.context, cfg.namespace)
-	cl, err := client.NewClient(conf)
+	if cfg.redrawRate <= 0 {
+		return fmt.Errorf("invalid max redraw rate %d: value must be positive", cfg.redrawRate)
+	}
+	listTable.MaxRedrawRate = cfg.redrawRate
+	mouse.Enabled = !cfg.noMouse
+	st, err := state.NewStore(state.DefaultPath())
+	if err != nil {
+		// State is nice to have, so broken state file doesn't block the app. It is kept as is, and nothing is saved
+		_, _ = fmt.Fprintf(os.Stderr, "%s, starting without saved state\n", err.Error())
+		st, _ = state.NewStore("")
+	}
+	settings, err := config.Load(cfg.config)
 	if err != nil {
 		return err
 	}
-	b := builder.NewBuilder(conf, cfg.kubectl, cfg.pager, cfg.editor)
-	application := app.NewApp(conf, cl, cl, b, executor.NewOsExecutor(), conf.Namespace())
-	return application.Run()
+	for name, width := range settings.MaxWidths {
+		listTable.MaxColumnWidths[name] = width
+	}
+	if err := actions.Configure(settings.KeyPreset, settings.Keys); err != nil {
+		return fmt.Errorf("invalid config %s: %w", cfg.config, err)
+	}
+	workspace.RestoreSession = !cfg.noSession
+	context, namespace := cfg.context, cfg.namespace
+	if context == "" && workspace.RestoreSession {
+		context = lastContext(st)
+	}
+	for {
+		workspace.RestoreNamespace = namespace == ""
+		conf := client.NewDefaultConfig(cfg.kubeconfig, context, namespace)
+		cl, err := client.NewClient(conf)
+		if err != nil {
+			return err
+		}
+		b := builder.NewBuilder(conf, cfg.kubectl, cfg.pager, cfg.editor)
+		application := app.NewApp(conf, cl, cl, b, executor.NewOsExecutor(), st, config.NewStore(cfg.config, settings), conf.Namespace())
+		if err := application.Run(); err != nil {
+			return err
+		}
+		// Context switched from the command line starts the app over
+		if context, namespace = application.NextContext(); context == "" {
+			return nil
+		}
+	}
+}
+
+// lastContext returns context of the last run if it is still in kubeconfig
+func lastContext(store commander.StateStore) string {
+	last := workspace.LastContext(store)
+	contexts, err := client.NewDefaultConfig(cfg.kubeconfig, "", "").Contexts()
+	if err != nil {
+		return ""
+	}
+	for _, context := range contexts {
+		if context == last {
+			return last
+		}
+	}
+	return ""
 }
@@ -4,13 +4,19 @@ type App interface {
 	Container
 	Run() error
 	Update()
+	PostFunc(f func())
 	Quit()
+	// SwitchContext stops the app, so it is started again with the context. Empty namespace means the one from context
+	SwitchContext(context string, namespace string)
 }
 
 type Container interface {
 	Client() Client
 	Config() Config
 	ResourceProvider() ResourceProvider
+	ResourceCache() ResourceCache
+	StateStore() StateStore
+	SettingsStore() SettingsStore
 	CommandBuilder() CommandBuilder
 	CommandExecutor() CommandExecutor
 	Screen() Screen
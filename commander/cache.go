@@ -0,0 +1,17 @@
+package commander
+
+// ResourceCache shares lists and watches of the same resources between widgets
+type ResourceCache interface {
+	// Subscribe starts receiving operations over resources of given namespace matching the selector.
+	// Rows which are already known are provided first
+	Subscribe(resource *Resource, namespace string, selector Selector) Subscription
+	// Refresh makes next subscriptions list resources again instead of sharing the known ones
+	Refresh(resource *Resource, namespace string, selector Selector)
+	// Stop stops listing and watching all resources
+	Stop()
+}
+
+type Subscription interface {
+	Provider() RowProvider
+	Close()
+}
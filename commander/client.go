@@ -4,6 +4,7 @@ import (
 	"context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
 )
@@ -12,7 +13,8 @@ type Client interface {
 	NewRequest(resource *Resource) (*rest.Request, error)
 	Get(ctx context.Context, resource *Resource, namespace string, name string, out runtime.Object) error
 	Delete(ctx context.Context, resource *Resource, namespace string, name string) error
-	List(ctx context.Context, resource *Resource, namespace string, out runtime.Object) error
-	ListAsTable(ctx context.Context, resource *Resource, namespace string) (*metav1.Table, error)
-	WatchAsTable(ctx context.Context, resource *Resource, namespace string) (watch.Interface, error)
+	Patch(ctx context.Context, resource *Resource, namespace string, name string, patchType types.PatchType, data []byte) error
+	List(ctx context.Context, resource *Resource, namespace string, opts metav1.ListOptions, out runtime.Object) error
+	ListAsTable(ctx context.Context, resource *Resource, namespace string, opts metav1.ListOptions, includeObject metav1.IncludeObjectPolicy) (*metav1.Table, error)
+	WatchAsTable(ctx context.Context, resource *Resource, namespace string, opts metav1.ListOptions, includeObject metav1.IncludeObjectPolicy) (watch.Interface, error)
 }
@@ -5,6 +5,8 @@ import "k8s.io/client-go/rest"
 type Config interface {
 	ClientConfig() (*rest.Config, error)
 	Context() string
+	// Contexts returns names of all contexts in kubeconfig
+	Contexts() ([]string, error)
 	Kubeconfig() string
 	Namespace() string
 }
@@ -24,6 +24,30 @@ type OpInitFinished struct{}
 
 func (o OpInitFinished) Operation() {}
 
+// OpInitProgress reports how many rows were loaded so far. Total is an estimate and could be zero when unknown
+type OpInitProgress struct {
+	Loaded int
+	Total  int
+}
+
+func (o OpInitProgress) Operation() {}
+
+type WatchState uint8
+
+const (
+	WatchNone WatchState = iota
+	WatchConnected
+	WatchReconnecting
+	WatchStale
+)
+
+// OpSetWatchState reports the state of the underlying watch connection
+type OpSetWatchState struct {
+	State WatchState
+}
+
+func (o OpSetWatchState) Operation() {}
+
 type OpAdded struct {
 	Row      Row
 	Index    *int
@@ -46,6 +70,8 @@ func (o OpDeleted) Operation() {}
 
 type OpSetColumns struct {
 	Columns []string
+	// Kubernetes column definitions, if any
+	Definitions []metav1.TableColumnDefinition
 }
 
 func (o OpSetColumns) Operation() {}
@@ -85,6 +111,10 @@ func (k KubernetesRow) Age() time.Duration {
 	return time.Now().Sub(k.md.CreationTimestamp.Time)
 }
 
+func (k KubernetesRow) CreationTime() time.Time {
+	return k.md.CreationTimestamp.Time
+}
+
 func (k KubernetesRow) Enabled() bool {
 	return true
 }
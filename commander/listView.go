@@ -12,6 +12,13 @@ type Row interface {
 
 type RowWithAge interface {
 	Age() time.Duration
+	// CreationTime doesn't change unlike the age, so rows are sorted by it
+	CreationTime() time.Time
+}
+
+// RowWithKeywords matches filter by its keywords as well as by its cells. For example, tree node matches by its children
+type RowWithKeywords interface {
+	Keywords() []string
 }
 
 type simpleRow struct {
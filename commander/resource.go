@@ -14,6 +14,8 @@ type ResourceProvider interface {
 type Resource struct {
 	Namespaced bool
 	Resource   string
+	// Short names from discovery, like "deploy" for deployments
+	ShortNames []string
 	Gk         schema.GroupKind
 	Gvk        schema.GroupVersionKind
 }
@@ -43,7 +45,12 @@ type ResourceContainer interface {
 	Status() StatusReporter
 	Client() Client
 	ResourceProvider() ResourceProvider
+	ResourceCache() ResourceCache
+	StateStore() StateStore
+	SettingsStore() SettingsStore
 	CommandBuilder() CommandBuilder
 	CommandExecutor() CommandExecutor
 	ScreenUpdater() ScreenUpdater
+	ShowPopup(title string, widget MaxSizeWidget)
+	FocusManager() FocusManager
 }
@@ -20,4 +20,6 @@ type StatusReporter interface {
 type ScreenUpdater interface {
 	UpdateScreen()
 	Resize()
+	// PostFunc executes function on the UI event loop
+	PostFunc(f func())
 }
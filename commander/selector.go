@@ -0,0 +1,35 @@
+package commander
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+)
+
+// Selector narrows resource lists down on the server side
+type Selector struct {
+	Label string `json:"label,omitempty"`
+	Field string `json:"field,omitempty"`
+}
+
+func (s Selector) Empty() bool {
+	return s.Label == "" && s.Field == ""
+}
+
+func (s Selector) ListOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: s.Label,
+		FieldSelector: s.Field,
+	}
+}
+
+// String renders selector in kubectl flags notation
+func (s Selector) String() string {
+	var parts []string
+	if s.Label != "" {
+		parts = append(parts, "-l "+s.Label)
+	}
+	if s.Field != "" {
+		parts = append(parts, "--field-selector "+s.Field)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,38 @@
+package commander
+
+// CustomColumn is a column evaluated against full objects, similar to kubectl -o custom-columns.
+// Value is either a JSONPath expression or a Go template
+type CustomColumn struct {
+	Name     string `json:"name"`
+	JSONPath string `json:"jsonPath,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+// Settings are user preferences read from the config file
+type Settings struct {
+	// Custom columns per resource kind, for example "Pod" or "Deployment.apps"
+	Columns map[string][]CustomColumn `json:"columns,omitempty"`
+	// Maximum widths of columns by name. "default" applies to columns which are not listed, 0 disables the limit
+	MaxWidths map[string]int `json:"maxWidths,omitempty"`
+	// Preset of additional keys, like "vim"
+	KeyPreset string `json:"keyPreset,omitempty"`
+	// Keys of actions by action id. Listed keys replace default ones
+	Keys map[string][]string `json:"keys,omitempty"`
+	// Resource types pinned to the top of the resource menu, in menu order
+	Favorites []Favorite `json:"favorites,omitempty"`
+}
+
+// Favorite is a resource type pinned to the resource menu
+type Favorite struct {
+	// Kind is written as "Kind" for core resources and "Kind.group" for the rest
+	Kind string `json:"kind"`
+	// Title replaces the default title of the resource type
+	Title string `json:"title,omitempty"`
+}
+
+// SettingsStore keeps settings which are changed from the app, like favorites
+type SettingsStore interface {
+	Settings() *Settings
+	// Save writes settings to the config file
+	Save() error
+}
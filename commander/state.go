@@ -0,0 +1,8 @@
+package commander
+
+// StateStore keeps UI state between runs
+type StateStore interface {
+	// Load reads a value stored under the key into out. Reports whether the value was found
+	Load(key string, out interface{}) bool
+	Save(key string, value interface{}) error
+}
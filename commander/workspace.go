@@ -4,8 +4,8 @@ type Workspace interface {
 	Widget
 	ResourceContainer
 	Init() error
-	ShowPopup(title string, widget MaxSizeWidget)
-	FocusManager() FocusManager
+	// SaveSession remembers the current view, so it is restored on the next start
+	SaveSession() error
 }
 
 type NamespaceAccessor interface {
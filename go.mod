@@ -12,10 +12,12 @@ require (
 	github.com/spf13/cast v1.3.1
 	github.com/spf13/cobra v0.0.7
 	google.golang.org/appengine v1.6.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.18.3
 	k8s.io/apimachinery v0.18.3
 	k8s.io/client-go v0.18.3
 	k8s.io/klog v1.0.0
 	k8s.io/kubectl v0.18.3
 	sigs.k8s.io/structured-merge-diff v0.0.0-20190525122527-15d366b2352e // indirect
+	sigs.k8s.io/yaml v1.2.0
 )